@@ -16,9 +16,14 @@ const (
 	cliIntentDiagnose                 = iota
 )
 
+const configEnvVariableName = "GLANCE_CONFIG"
+
 type cliOptions struct {
 	intent     cliIntent
 	configPath string
+	// when no config path is explicitly provided and GLANCE_CONFIG is set,
+	// the entire config is read from that variable instead of a file
+	configFromEnv bool
 }
 
 func parseCliOptions() (*cliOptions, error) {
@@ -34,12 +39,21 @@ func parseCliOptions() (*cliOptions, error) {
 		fmt.Println("  config:print        Print the parsed config file with embedded includes")
 		fmt.Println("  diagnose            Run diagnostic checks")
 	}
-	configPath := flags.String("config", "glance.yml", "Set config path")
+	configPath := flags.String("config", "glance.yml", "Set config path (if not set, "+configEnvVariableName+" is used when present)")
 	err := flags.Parse(os.Args[1:])
 	if err != nil {
 		return nil, err
 	}
 
+	configPathProvided := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			configPathProvided = true
+		}
+	})
+
+	_, configInEnv := os.LookupEnv(configEnvVariableName)
+
 	var intent cliIntent
 	var args = flags.Args()
 	unknownCommandErr := fmt.Errorf("unknown command: %s", strings.Join(args, " "))
@@ -61,7 +75,8 @@ func parseCliOptions() (*cliOptions, error) {
 	}
 
 	return &cliOptions{
-		intent:     intent,
-		configPath: *configPath,
+		intent:        intent,
+		configPath:    *configPath,
+		configFromEnv: !configPathProvided && configInEnv,
 	}, nil
 }
@@ -0,0 +1,116 @@
+package glance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Reads a secret such as a password from a credential store, given the name
+// that the secret is stored under. Errors must not include the secret.
+type credentialStore func(ctx context.Context, key string) (string, error)
+
+// Stores that can be used with ${smtp-password:store:key}, new ones only need to be added here
+var credentialStores = map[string]credentialStore{
+	// pass stores the password on the first line, followed by optional metadata
+	"pass": commandCredentialStore(firstLineOfOutput, "pass", "show"),
+	// gopass can output just the password on its own
+	"gopass": commandCredentialStore(firstLineOfOutput, "gopass", "show", "--password"),
+}
+
+const credentialStoreTimeout = 10 * time.Second
+
+func readFromCredentialStore(storeName, key string) (string, error) {
+	store, exists := credentialStores[storeName]
+	if !exists {
+		return "", fmt.Errorf(
+			"unknown credential store %s for config variable with key %s, available stores are %s",
+			storeName, key, strings.Join(slices.Sorted(maps.Keys(credentialStores)), ", "),
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), credentialStoreTimeout)
+	defer cancel()
+
+	value, err := store(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("reading %s from credential store %s: %v", key, storeName, err)
+	}
+
+	return value, nil
+}
+
+func commandCredentialStore(parseOutput func([]byte) string, name string, args ...string) credentialStore {
+	return func(ctx context.Context, key string) (string, error) {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return "", fmt.Errorf("%s is not installed or not in PATH", name)
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, path, append(args, key)...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", fmt.Errorf("%s did not finish within %s", name, credentialStoreTimeout)
+			}
+
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				return "", fmt.Errorf("%s: %s", err, message)
+			}
+
+			return "", err
+		}
+
+		value := parseOutput(stdout.Bytes())
+		if value == "" {
+			return "", fmt.Errorf("%s returned an empty value", name)
+		}
+
+		return value, nil
+	}
+}
+
+func firstLineOfOutput(output []byte) string {
+	line, _, _ := bytes.Cut(output, []byte("\n"))
+	return strings.TrimSuffix(string(line), "\r")
+}
+
+// Parses the store:key part of a ${smtp-password:store:key} reference, returning its
+// length including the closing brace. Unlike other keys, credential keys are free-form
+// since stores name their entries with paths such as email/smtp.example.com:587:user
+func parseCredentialReference(input []byte) (store, key string, length int, ok bool) {
+	storeEnd := bytes.IndexByte(input, ':')
+	if storeEnd < 1 {
+		return "", "", 0, false
+	}
+
+	for i, c := range input[:storeEnd] {
+		if !isConfigVarTypeChar(c, i == 0) {
+			return "", "", 0, false
+		}
+	}
+
+	for i := storeEnd + 1; i < len(input); i++ {
+		switch c := input[i]; {
+		case c == '}':
+			if i == storeEnd+1 {
+				return "", "", 0, false
+			}
+
+			return string(input[:storeEnd]), string(input[storeEnd+1 : i]), i + 1, true
+		case c <= ' ' || c == 0x7f || strings.IndexByte("${\\\"'", c) != -1:
+			return "", "", 0, false
+		}
+	}
+
+	return "", "", 0, false
+}
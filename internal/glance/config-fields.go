@@ -2,7 +2,10 @@ package glance
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -31,51 +34,169 @@ func (c *hslColorField) String() string {
 	return fmt.Sprintf("hsl(%d, %d%%, %d%%)", c.Hue, c.Saturation, c.Lightness)
 }
 
+func (c *hslColorField) clone() *hslColorField {
+	if c == nil {
+		return nil
+	}
+
+	clone := *c
+	return &clone
+}
+
 func (c *hslColorField) UnmarshalYAML(node *yaml.Node) error {
 	var value string
 
 	if err := node.Decode(&value); err != nil {
-		return err
+		return &hslColorError{line: node.Line, column: node.Column, err: errors.New("value is not a string")}
 	}
 
-	matches := hslColorFieldPattern.FindStringSubmatch(value)
+	parsed, err := parseHSLColor(value)
+	if err != nil {
+		return &hslColorError{line: node.Line, column: node.Column, value: value, err: err}
+	}
 
-	if len(matches) != 4 {
-		return fmt.Errorf("invalid HSL color format: %s", value)
+	*c = *parsed
+
+	return nil
+}
+
+const hslColorFormatHint = `expected hue (0-360), saturation (0-100) and lightness (0-100) separated by spaces, such as "240 13 20"`
+
+// The property which an invalid color was set on isn't known while unmarshaling, so
+// the position of the value is kept in order to look up its path in the config later
+type hslColorError struct {
+	line   int
+	column int
+	value  string
+	err    error
+	path   string
+}
+
+func (e *hslColorError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "line %d: invalid color", e.line)
+
+	if e.value != "" {
+		fmt.Fprintf(&b, " %q", e.value)
 	}
 
-	hue, err := strconv.ParseUint(matches[1], 10, 16)
-	if err != nil {
-		return err
+	if e.path != "" {
+		fmt.Fprintf(&b, " for %s", e.path)
 	}
 
-	if hue > hslHueMax {
-		return fmt.Errorf("HSL hue must be between 0 and %d", hslHueMax)
+	fmt.Fprintf(&b, ": %v, %s", e.err, hslColorFormatHint)
+
+	return b.String()
+}
+
+// Adds the path of the property to the error if it was caused by an invalid color
+func withHSLColorErrorPath(contents []byte, err error) error {
+	var colorErr *hslColorError
+	if !errors.As(err, &colorErr) {
+		return err
 	}
 
-	saturation, err := strconv.ParseUint(matches[2], 10, 8)
-	if err != nil {
+	var root yaml.Node
+	if yaml.Unmarshal(contents, &root) != nil {
 		return err
 	}
 
-	if saturation > hslSaturationMax {
-		return fmt.Errorf("HSL saturation must be between 0 and %d", hslSaturationMax)
+	for _, document := range root.Content {
+		if path, found := yamlPathOfNodeAt(document, colorErr.line, colorErr.column); found {
+			colorErr.path = strings.Join(path, ".")
+			break
+		}
 	}
 
-	lightness, err := strconv.ParseUint(matches[3], 10, 8)
-	if err != nil {
-		return err
+	return err
+}
+
+// Items of sequences are referred to by their position, starting from 1
+func yamlPathOfNodeAt(node *yaml.Node, line, column int) ([]string, bool) {
+	if node.Line == line && node.Column == column {
+		return nil, true
 	}
 
-	if lightness > hslLightnessMax {
-		return fmt.Errorf("HSL lightness must be between 0 and %d", hslLightnessMax)
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if path, found := yamlPathOfNodeAt(node.Content[i+1], line, column); found {
+				return append([]string{node.Content[i].Value}, path...), true
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if path, found := yamlPathOfNodeAt(item, line, column); found {
+				return append([]string{strconv.Itoa(i + 1)}, path...), true
+			}
+		}
 	}
 
-	c.Hue = uint16(hue)
-	c.Saturation = uint8(saturation)
-	c.Lightness = uint8(lightness)
+	return nil, false
+}
 
-	return nil
+// Returns the color in the #rrggbb format
+func (c *hslColorField) toHex() string {
+	h := float64(c.Hue) / 60
+	s := float64(c.Saturation) / hslSaturationMax
+	l := float64(c.Lightness) / hslLightnessMax
+
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+
+	var r, g, b float64
+	switch {
+	case h < 1:
+		r, g, b = chroma, x, 0
+	case h < 2:
+		r, g, b = x, chroma, 0
+	case h < 3:
+		r, g, b = 0, chroma, x
+	case h < 4:
+		r, g, b = 0, x, chroma
+	case h < 5:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	m := l - chroma/2
+	toByte := func(v float64) uint8 {
+		return uint8(math.Round((v + m) * 255))
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", toByte(r), toByte(g), toByte(b))
+}
+
+func parseHSLColor(value string) (*hslColorField, error) {
+	matches := hslColorFieldPattern.FindStringSubmatch(value)
+
+	if len(matches) != 4 {
+		return nil, errors.New("unrecognized format")
+	}
+
+	// the pattern only matches up to 3 digits so these can't fail
+	hue, _ := strconv.ParseUint(matches[1], 10, 16)
+	saturation, _ := strconv.ParseUint(matches[2], 10, 16)
+	lightness, _ := strconv.ParseUint(matches[3], 10, 16)
+
+	if hue > hslHueMax {
+		return nil, fmt.Errorf("hue is %d but must be between 0 and %d", hue, hslHueMax)
+	}
+
+	if saturation > hslSaturationMax {
+		return nil, fmt.Errorf("saturation is %d but must be between 0 and %d", saturation, hslSaturationMax)
+	}
+
+	if lightness > hslLightnessMax {
+		return nil, fmt.Errorf("lightness is %d but must be between 0 and %d", lightness, hslLightnessMax)
+	}
+
+	return &hslColorField{
+		Hue:        uint16(hue),
+		Saturation: uint8(saturation),
+		Lightness:  uint8(lightness),
+	}, nil
 }
 
 var durationFieldPattern = regexp.MustCompile(`^(\d+)(s|m|h|d)$`)
@@ -177,9 +298,27 @@ type proxyOptionsField struct {
 	URL           string        `yaml:"url"`
 	AllowInsecure bool          `yaml:"allow-insecure"`
 	Timeout       durationField `yaml:"timeout"`
+	parsedURL     *url.URL      `yaml:"-"`
 	client        *http.Client  `yaml:"-"`
 }
 
+// Builds the client from the widget's clients, so that apart from going through the proxy
+// its requests get the same connection settings, headers and user agent as any other
+func (p *proxyOptionsField) setClientFrom(clients *widgetHTTPClients) {
+	if p.parsedURL == nil || clients == nil {
+		return
+	}
+
+	var timeout = defaultClientTimeout
+	if p.Timeout > 0 {
+		timeout = time.Duration(p.Timeout)
+	}
+
+	p.client = clients.newClient(timeout, p.AllowInsecure, func(transport *http.Transport) {
+		transport.Proxy = http.ProxyURL(p.parsedURL)
+	})
+}
+
 func (p *proxyOptionsField) UnmarshalYAML(node *yaml.Node) error {
 	type proxyOptionsFieldAlias proxyOptionsField
 	alias := (*proxyOptionsFieldAlias)(p)
@@ -204,19 +343,116 @@ func (p *proxyOptionsField) UnmarshalYAML(node *yaml.Node) error {
 		return fmt.Errorf("parsing proxy URL: %v", err)
 	}
 
-	var timeout = defaultClientTimeout
-	if p.Timeout > 0 {
-		timeout = time.Duration(p.Timeout)
+	p.parsedURL = parsedUrl
+	return nil
+}
+
+type cacheConfigField struct {
+	Backend   string `yaml:"backend"`
+	KeyPrefix string `yaml:"key-prefix"`
+	Redis     struct {
+		Address  string `yaml:"address"`
+		Password string `yaml:"password"`
+		DB       int    `yaml:"db"`
+	} `yaml:"redis"`
+}
+
+type httpTransportOptionsField struct {
+	DialTimeout   durationField `yaml:"dial-timeout"`
+	TLSSkipVerify bool          `yaml:"tls-skip-verify"`
+	KeepAlive     durationField `yaml:"keep-alive"`
+	MaxIdleConns  int           `yaml:"max-idle-conns"`
+}
+
+func (o *httpTransportOptionsField) newTransport(
+	allowInsecure bool,
+	proxy func(*http.Request) (*url.URL, error),
+	clientCertificate *tls.Certificate,
+) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if proxy != nil {
+		transport.Proxy = proxy
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	if o.DialTimeout > 0 {
+		dialer.Timeout = time.Duration(o.DialTimeout)
 	}
 
-	p.client = &http.Client{
-		Timeout: timeout,
-		Transport: &http.Transport{
-			Proxy:           http.ProxyURL(parsedUrl),
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: p.AllowInsecure},
-		},
+	if o.KeepAlive > 0 {
+		dialer.KeepAlive = time.Duration(o.KeepAlive)
 	}
 
+	transport.DialContext = dialer.DialContext
+
+	if o.MaxIdleConns > 0 {
+		transport.MaxIdleConns = o.MaxIdleConns
+	}
+
+	if allowInsecure || o.TLSSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	if clientCertificate != nil {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+
+		transport.TLSClientConfig.Certificates = []tls.Certificate{*clientCertificate}
+	}
+
+	return transport
+}
+
+// Doesn't fail decoding on invalid values so that they can be reported with a clearer
+// error, since the port often comes from a variable such as ${PORT} where the decoding
+// error wouldn't make it obvious what the value was
+type portField struct {
+	Number       uint16
+	isSet        bool
+	isInvalid    bool
+	invalidValue string
+}
+
+func (f *portField) UnmarshalYAML(node *yaml.Node) error {
+	f.isSet = true
+
+	number, err := strconv.ParseUint(strings.TrimSpace(node.Value), 10, 16)
+	if node.Kind != yaml.ScalarNode || err != nil {
+		f.isInvalid = true
+		f.invalidValue = node.Value
+		return nil
+	}
+
+	f.Number = uint16(number)
+	return nil
+}
+
+// Either a single directory or a list of directories
+type assetsPathField []string
+
+func (f *assetsPathField) UnmarshalYAML(node *yaml.Node) error {
+	var path string
+
+	if err := node.Decode(&path); err == nil {
+		if path != "" {
+			*f = assetsPathField{path}
+		}
+
+		return nil
+	}
+
+	var paths []string
+	if err := node.Decode(&paths); err != nil {
+		return fmt.Errorf("assets-path must be either a path or a list of paths")
+	}
+
+	*f = paths
 	return nil
 }
 
@@ -274,3 +510,144 @@ func (q *queryParametersField) toQueryString() string {
 
 	return query.Encode()
 }
+
+var scheduleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+var scheduleHoursPattern = regexp.MustCompile(`^(\d{2}):(\d{2})-(\d{2}):(\d{2})$`)
+
+// Minutes since midnight, end is exclusive and may be smaller than
+// start for ranges that cross midnight, such as 22:00-02:00
+type scheduleHoursRange struct {
+	start int
+	end   int
+}
+
+type scheduleField struct {
+	days     [7]bool
+	hours    []scheduleHoursRange
+	location *time.Location
+}
+
+func (s *scheduleField) UnmarshalYAML(node *yaml.Node) error {
+	var value struct {
+		Days     []string `yaml:"days"`
+		Hours    []string `yaml:"hours"`
+		Timezone string   `yaml:"timezone"`
+	}
+
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+
+	if value.Timezone != "" {
+		location, err := time.LoadLocation(value.Timezone)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid timezone %s, must be a name such as Europe/London", node.Line, value.Timezone)
+		}
+
+		s.location = location
+	}
+
+	if len(value.Days) == 0 {
+		for i := range s.days {
+			s.days[i] = true
+		}
+	}
+
+	for _, days := range value.Days {
+		from, to, isRange := strings.Cut(days, "-")
+		if !isRange {
+			to = from
+		}
+
+		start, ok := scheduleWeekdays[strings.ToLower(strings.TrimSpace(from))]
+		end, ok2 := scheduleWeekdays[strings.ToLower(strings.TrimSpace(to))]
+		if !ok || !ok2 {
+			return fmt.Errorf("line %d: invalid days %s, must be a day such as Mon or a range such as Mon-Fri", node.Line, days)
+		}
+
+		// ranges can wrap around the end of the week, such as Fri-Mon
+		for day := start; ; day = (day + 1) % 7 {
+			s.days[day] = true
+			if day == end {
+				break
+			}
+		}
+	}
+
+	for _, hours := range value.Hours {
+		matches := scheduleHoursPattern.FindStringSubmatch(strings.TrimSpace(hours))
+		if matches == nil {
+			return fmt.Errorf("line %d: invalid hours %s, must be a range such as 08:00-18:00", node.Line, hours)
+		}
+
+		var r scheduleHoursRange
+		var err error
+		if r.start, err = parseScheduleTime(matches[1], matches[2]); err != nil {
+			return fmt.Errorf("line %d: invalid hours %s: %v", node.Line, hours, err)
+		}
+
+		if r.end, err = parseScheduleTime(matches[3], matches[4]); err != nil {
+			return fmt.Errorf("line %d: invalid hours %s: %v", node.Line, hours, err)
+		}
+
+		if r.start == r.end {
+			return fmt.Errorf("line %d: invalid hours %s, start and end cannot be the same", node.Line, hours)
+		}
+
+		s.hours = append(s.hours, r)
+	}
+
+	return nil
+}
+
+func parseScheduleTime(hours, minutes string) (int, error) {
+	h, _ := strconv.Atoi(hours)
+	m, _ := strconv.Atoi(minutes)
+
+	if m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("%s:%s is not a valid time", hours, minutes)
+	}
+
+	return h*60 + m, nil
+}
+
+// Uses the server's local timezone unless a timezone is set, the local
+// timezone can be changed through the TZ environment variable
+func (s *scheduleField) isActive(now time.Time) bool {
+	if s.location != nil {
+		now = now.In(s.location)
+	} else {
+		now = now.Local()
+	}
+
+	if !s.days[now.Weekday()] {
+		return false
+	}
+
+	if len(s.hours) == 0 {
+		return true
+	}
+
+	minutes := now.Hour()*60 + now.Minute()
+
+	for _, r := range s.hours {
+		if r.start < r.end {
+			if minutes >= r.start && minutes < r.end {
+				return true
+			}
+		} else if minutes >= r.start || minutes < r.end {
+			return true
+		}
+	}
+
+	return false
+}
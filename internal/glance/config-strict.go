@@ -0,0 +1,215 @@
+package glance
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	yamlUnmarshalerType     = reflect.TypeFor[yaml.Unmarshaler]()
+	widgetsType             = reflect.TypeFor[widgets]()
+	widgetDefinitionsType   = reflect.TypeFor[widgetDefinitions]()
+	strictConfigSkippedKeys = map[string]struct{}{"<<": {}}
+)
+
+// Widgets get decoded from within their own UnmarshalYAML using node.Decode, which
+// doesn't report unknown fields even when the config is decoded with KnownFields,
+// so the fields get checked separately by walking the nodes of the config. This also
+// allows reporting all unknown fields at once with more readable errors.
+func findUnknownConfigFields(contents []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(contents, &root); err != nil {
+		return err
+	}
+
+	var unknown []string
+	for _, document := range root.Content {
+		collectUnknownFields(document, reflect.TypeFor[config](), "", &unknown)
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("config contains unknown fields:\n  %s", strings.Join(unknown, "\n  "))
+	}
+
+	return nil
+}
+
+// The context describes where the node is, such as "rss widget", for use in errors
+func collectUnknownFields(node *yaml.Node, t reflect.Type, context string, unknown *[]string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case widgetsType:
+		if node.Kind == yaml.SequenceNode {
+			for _, item := range node.Content {
+				collectUnknownWidgetFields(item, unknown)
+			}
+		}
+		return
+	case widgetDefinitionsType:
+		if node.Kind == yaml.MappingNode {
+			for i := 1; i < len(node.Content); i += 2 {
+				collectUnknownWidgetFields(node.Content[i], unknown)
+			}
+		}
+		return
+	}
+
+	// anything with custom decoding can accept values of any shape
+	if reflect.PointerTo(t).Implements(yamlUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+
+		fields, acceptsAny := yamlFieldsOfStruct(t)
+		if acceptsAny {
+			return
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if _, skipped := strictConfigSkippedKeys[key.Value]; skipped {
+				continue
+			}
+
+			fieldType, exists := fields[key.Value]
+			if !exists {
+				if context != "" {
+					*unknown = append(*unknown, fmt.Sprintf("line %d: unknown field %s in %s", key.Line, key.Value, context))
+				} else {
+					*unknown = append(*unknown, fmt.Sprintf("line %d: unknown field %s", key.Line, key.Value))
+				}
+				continue
+			}
+
+			collectUnknownFields(node.Content[i+1], fieldType, context, unknown)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind == yaml.SequenceNode {
+			for _, item := range node.Content {
+				collectUnknownFields(item, t.Elem(), context, unknown)
+			}
+		}
+	case reflect.Map:
+		if node.Kind == yaml.MappingNode {
+			for i := 1; i < len(node.Content); i += 2 {
+				collectUnknownFields(node.Content[i], t.Elem(), context, unknown)
+			}
+		}
+	}
+}
+
+func collectUnknownWidgetFields(node *yaml.Node, unknown *[]string) {
+	meta := struct {
+		Type string `yaml:"type"`
+		Use  string `yaml:"use"`
+	}{}
+
+	if err := node.Decode(&meta); err != nil {
+		return
+	}
+
+	var w widget
+	var context string
+
+	if meta.Use != "" {
+		w = &widgetReference{}
+		context = "widget using definition " + meta.Use
+	} else {
+		var err error
+		// unknown widget types already result in an error when initializing the widgets
+		if w, err = newWidget(meta.Type); err != nil {
+			return
+		}
+
+		context = meta.Type + " widget"
+		node = withoutMappingKey(node, "headers")
+	}
+
+	collectUnknownFields(node, reflect.TypeOf(w), context, unknown)
+}
+
+// Returns a copy of the mapping without the given key, for keys which are
+// decoded for every widget in newWidgetFromYAMLNode rather than by the widget
+func withoutMappingKey(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return node
+	}
+
+	copied := *node
+	copied.Content = make([]*yaml.Node, 0, len(node.Content))
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != key {
+			copied.Content = append(copied.Content, node.Content[i], node.Content[i+1])
+		}
+	}
+
+	return &copied
+}
+
+// Returns the types of all fields by the key they are decoded from, including the
+// fields of inlined structs. acceptsAny is true if the struct has an inlined map.
+func yamlFieldsOfStruct(t reflect.Type) (fields map[string]reflect.Type, acceptsAny bool) {
+	fields = make(map[string]reflect.Type)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if strings.Contains(options, "inline") {
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+
+			if fieldType.Kind() == reflect.Map {
+				return nil, true
+			}
+
+			inlined, inlinedAcceptsAny := yamlFieldsOfStruct(fieldType)
+			if inlinedAcceptsAny {
+				return nil, true
+			}
+
+			for key, inlinedType := range inlined {
+				fields[key] = inlinedType
+			}
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		fields[name] = field.Type
+	}
+
+	return fields, false
+}
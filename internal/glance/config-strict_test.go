@@ -0,0 +1,185 @@
+package glance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindUnknownConfigFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name: "known fields",
+			config: `
+server:
+  port: 8080
+pages:
+  - name: Home
+    columns:
+      - size: full
+        widgets:
+          - type: clock
+            title: Time
+            timeout: 5s
+`,
+		},
+		{
+			name: "top level and nested outside of widgets",
+			config: `
+server:
+  prot: 8080
+unknown: true
+pages:
+  - name: Home
+    sluug: home
+`,
+			want: []string{
+				"line 3: unknown field prot",
+				"line 4: unknown field unknown",
+				"line 7: unknown field sluug",
+			},
+		},
+		{
+			name: "widget",
+			config: `
+pages:
+  - name: Home
+    columns:
+      - size: full
+        widgets:
+          - type: rss
+            limitt: 5
+            feeds:
+              - url: https://example.com/feed
+                titel: Example
+`,
+			want: []string{
+				"line 8: unknown field limitt in rss widget",
+				"line 11: unknown field titel in rss widget",
+			},
+		},
+		{
+			name: "widgets nested in other widgets",
+			config: `
+pages:
+  - name: Home
+    columns:
+      - size: full
+        widgets:
+          - type: group
+            widgets:
+              - type: split-column
+                widgets:
+                  - type: clock
+                    hour-formatt: 24h
+              - type: bookmarks
+                groups:
+                  - links:
+                      - title: Example
+                        url: https://example.com
+                        iconn: si:github
+`,
+			want: []string{
+				"line 12: unknown field hour-formatt in clock widget",
+				"line 18: unknown field iconn in bookmarks widget",
+			},
+		},
+		{
+			name: "widget definitions and their uses",
+			config: `
+definitions:
+  feeds:
+    type: rss
+    limitt: 5
+pages:
+  - name: Home
+    columns:
+      - size: full
+        widgets:
+          - use: feeds
+            titel: Feeds
+`,
+			want: []string{
+				"line 5: unknown field limitt in rss widget",
+				"line 12: unknown field titel in widget using definition feeds",
+			},
+		},
+		{
+			name: "headers are allowed for every widget",
+			config: `
+pages:
+  - name: Home
+    columns:
+      - size: full
+        widgets:
+          - type: clock
+            headers:
+              Authorization: token
+          - type: custom-api
+            url: https://example.com
+            headers:
+              Accept: application/json
+            subrequests:
+              other:
+                url: https://example.com/other
+                headerz:
+                  Accept: application/json
+`,
+			want: []string{
+				"line 17: unknown field headerz in custom-api widget",
+			},
+		},
+		{
+			name: "merge keys",
+			config: `
+pages:
+  - name: Home
+    columns:
+      - size: full
+        widgets:
+          - &clock
+            type: clock
+          - <<: *clock
+            title: Second clock
+`,
+		},
+		{
+			name: "unknown widget types are left to widget initialization",
+			config: `
+pages:
+  - name: Home
+    columns:
+      - size: full
+        widgets:
+          - type: not-a-widget
+            anything: true
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := findUnknownConfigFields([]byte(test.config))
+
+			if len(test.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			got := strings.Split(err.Error(), "\n  ")[1:]
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Fatalf("got unknown fields:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
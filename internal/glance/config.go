@@ -2,16 +2,30 @@ package glance
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"log"
+	"log/slog"
 	"maps"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	texttemplate "text/template"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fsnotify/fsnotify"
 	"gopkg.in/yaml.v3"
@@ -19,377 +33,2587 @@ import (
 
 type config struct {
 	Server struct {
-		Host       string    `yaml:"host"`
-		Port       uint16    `yaml:"port"`
-		AssetsPath string    `yaml:"assets-path"`
-		BaseURL    string    `yaml:"base-url"`
-		StartedAt  time.Time `yaml:"-"` // used in custom css file
+		Host       string          `yaml:"host"`
+		Port       portField       `yaml:"port"`
+		Socket     string          `yaml:"socket"`
+		SocketMode string          `yaml:"socket-mode"`
+		AssetsPath assetsPathField `yaml:"assets-path"`
+		BaseURL    string          `yaml:"base-url"`
+
+		AssetFingerprinting  bool              `yaml:"asset-fingerprinting"`
+		DisableRoutes        []string          `yaml:"disable-routes"`
+		FetchJitter          durationField     `yaml:"fetch-jitter"`
+		WidgetRefreshJitter  durationField     `yaml:"widget-refresh-jitter"`
+		MinRefreshInterval   durationField     `yaml:"min-refresh-interval"`
+		MaxWidgetsPerPage    int               `yaml:"max-widgets-per-page"`
+		Proxy                string            `yaml:"proxy"`
+		NoProxy              []string          `yaml:"no-proxy"`
+		Strict               bool              `yaml:"strict"`
+		StartupCheck         bool              `yaml:"startup-check"`
+		HotReloadWebhook     string            `yaml:"hot-reload-webhook"`
+		HotReloadDelay       durationField     `yaml:"hot-reload-delay"`
+		ShutdownTimeout      durationField     `yaml:"shutdown-timeout"`
+		WatchEnvVars         []string          `yaml:"watch-env-vars"`
+		EnvPollInterval      durationField     `yaml:"env-poll-interval"`
+		Headers              map[string]string `yaml:"headers"`
+		UserAgent            *string           `yaml:"user-agent"`
+		RequestIDHeader      string            `yaml:"request-id-header"`
+		LogLevel             string            `yaml:"log-level"`
+		Locale               string            `yaml:"locale"`
+		EnableETag           bool              `yaml:"enable-etag"`
+		ExperimentalFeatures []string          `yaml:"experimental-features"`
+		DemoMode             bool              `yaml:"demo-mode"`
+
+		WidgetHTTPTransport httpTransportOptionsField `yaml:"widget-http-transport"`
+		Cache               cacheConfigField          `yaml:"cache"`
+
+		TLS struct {
+			CertFile string `yaml:"cert-file"`
+			KeyFile  string `yaml:"key-file"`
+		} `yaml:"tls"`
+
+		// sent by widgets to servers which require a client certificate
+		HTTPClientCertFile    string           `yaml:"http-client-cert-file"`
+		HTTPClientKeyFile     string           `yaml:"http-client-key-file"`
+		httpClientCertificate *tls.Certificate `yaml:"-"`
+
+		Metrics struct {
+			Enabled bool   `yaml:"enabled"`
+			Token   string `yaml:"token"`
+		} `yaml:"metrics"`
+
+		DebugConfig struct {
+			Enabled bool   `yaml:"enabled"`
+			Token   string `yaml:"token"`
+		} `yaml:"debug-config"`
 	} `yaml:"server"`
 
 	Document struct {
-		Head template.HTML `yaml:"head"`
+		Head             template.HTML `yaml:"head"`
+		TabTitleTemplate string        `yaml:"tab-title-template"`
 	} `yaml:"document"`
 
-	Theme struct {
-		BackgroundColor          *hslColorField `yaml:"background-color"`
-		PrimaryColor             *hslColorField `yaml:"primary-color"`
-		PositiveColor            *hslColorField `yaml:"positive-color"`
-		NegativeColor            *hslColorField `yaml:"negative-color"`
-		Light                    bool           `yaml:"light"`
-		ContrastMultiplier       float32        `yaml:"contrast-multiplier"`
-		TextSaturationMultiplier float32        `yaml:"text-saturation-multiplier"`
-		CustomCSSFile            string         `yaml:"custom-css-file"`
-	} `yaml:"theme"`
+	Theme themeProperties `yaml:"theme"`
 
 	Branding struct {
-		HideFooter   bool          `yaml:"hide-footer"`
-		CustomFooter template.HTML `yaml:"custom-footer"`
-		LogoText     string        `yaml:"logo-text"`
-		LogoURL      string        `yaml:"logo-url"`
-		FaviconURL   string        `yaml:"favicon-url"`
+		HideFooter       bool          `yaml:"hide-footer"`
+		CustomFooter     template.HTML `yaml:"custom-footer"`
+		LogoText         string        `yaml:"logo-text"`
+		LogoURL          string        `yaml:"logo-url"`
+		FaviconURL       string        `yaml:"favicon-url"`
+		CustomScriptFile string        `yaml:"custom-script-file"`
+		NavCollapsed     bool          `yaml:"nav-collapsed"`
+
+		Banner struct {
+			Content  template.HTML `yaml:"content"`
+			Severity string        `yaml:"severity"`
+		} `yaml:"banner"`
 	} `yaml:"branding"`
 
-	Pages []page `yaml:"pages"`
+	Version     int               `yaml:"config-version"`
+	Definitions widgetDefinitions `yaml:"definitions"`
+	Pages       []page            `yaml:"pages"`
+
+	pageIndex            map[string]*page
+	experimentalFeatures map[string]bool
+	// the config with secrets redacted, only set when server.debug-config is enabled
+	debugContents []byte
+}
+
+// Incremented whenever options get deprecated or change in backwards incompatible ways,
+// configs without a config-version are considered to be at version 1
+const currentConfigVersion = 1
+
+const configVersionMigrationGuideURL = "https://github.com/glanceapp/glance/blob/main/docs/configuration.md#config-version"
+
+// The options which got deprecated with each version, keyed by the version they were deprecated in
+var configVersionDeprecations = map[int][]string{}
+
+// Logs a warning if the config was written for a different version than the current one so that
+// users get pointed in the right direction before any errors caused by outdated options.
+func warnAboutConfigVersionMismatch(version int) {
+	if version == currentConfigVersion {
+		return
+	}
+
+	if version > currentConfigVersion {
+		log.Printf(
+			"Warning: config-version %d is newer than the latest version supported by this release of Glance (%d), some options may not be recognized",
+			version, currentConfigVersion,
+		)
+		return
+	}
+
+	log.Printf("Warning: config is at version %d while the current version is %d", version, currentConfigVersion)
+
+	for v := version + 1; v <= currentConfigVersion; v++ {
+		for _, deprecation := range configVersionDeprecations[v] {
+			log.Printf("  deprecated in version %d: %s", v, deprecation)
+		}
+	}
+
+	log.Printf("See %s for how to migrate your config", configVersionMigrationGuideURL)
+}
+
+type themeProperties struct {
+	BackgroundColor          *hslColorField `yaml:"background-color"`
+	PrimaryColor             *hslColorField `yaml:"primary-color"`
+	PositiveColor            *hslColorField `yaml:"positive-color"`
+	NegativeColor            *hslColorField `yaml:"negative-color"`
+	HighlightColor           *hslColorField `yaml:"highlight-color"`
+	Light                    bool           `yaml:"light"`
+	ContrastMultiplier       float32        `yaml:"contrast-multiplier"`
+	TextSaturationMultiplier float32        `yaml:"text-saturation-multiplier"`
+	CustomCSSFile            string         `yaml:"custom-css-file"`
+	WidgetHeaderAlign        string         `yaml:"widget-header-align"`
+	TooltipStyle             string         `yaml:"tooltip-style"`
+	NavTextTransform         string         `yaml:"nav-text-transform"`
+}
+
+// Overrides for the global theme on a single page, unset fields fall back to the global values
+type pageThemeOverrides struct {
+	BackgroundColor          *hslColorField `yaml:"background-color"`
+	PrimaryColor             *hslColorField `yaml:"primary-color"`
+	PositiveColor            *hslColorField `yaml:"positive-color"`
+	NegativeColor            *hslColorField `yaml:"negative-color"`
+	HighlightColor           *hslColorField `yaml:"highlight-color"`
+	Light                    *bool          `yaml:"light"`
+	ContrastMultiplier       float32        `yaml:"contrast-multiplier"`
+	TextSaturationMultiplier float32        `yaml:"text-saturation-multiplier"`
+}
+
+func (o *pageThemeOverrides) clone() *pageThemeOverrides {
+	if o == nil {
+		return nil
+	}
+
+	clone := *o
+	clone.BackgroundColor = o.BackgroundColor.clone()
+	clone.PrimaryColor = o.PrimaryColor.clone()
+	clone.PositiveColor = o.PositiveColor.clone()
+	clone.NegativeColor = o.NegativeColor.clone()
+	clone.HighlightColor = o.HighlightColor.clone()
+
+	if o.Light != nil {
+		light := *o.Light
+		clone.Light = &light
+	}
+
+	return &clone
+}
+
+// Returns a copy of the theme with the overrides applied
+func (t themeProperties) withOverrides(o *pageThemeOverrides) themeProperties {
+	if o == nil {
+		return t
+	}
+
+	if o.BackgroundColor != nil {
+		t.BackgroundColor = o.BackgroundColor
+	}
+
+	if o.PrimaryColor != nil {
+		t.PrimaryColor = o.PrimaryColor
+	}
+
+	if o.PositiveColor != nil {
+		t.PositiveColor = o.PositiveColor
+	}
+
+	if o.NegativeColor != nil {
+		t.NegativeColor = o.NegativeColor
+	}
+
+	if o.HighlightColor != nil {
+		t.HighlightColor = o.HighlightColor
+	}
+
+	if o.Light != nil {
+		t.Light = *o.Light
+	}
+
+	if o.ContrastMultiplier != 0 {
+		t.ContrastMultiplier = o.ContrastMultiplier
+	}
+
+	if o.TextSaturationMultiplier != 0 {
+		t.TextSaturationMultiplier = o.TextSaturationMultiplier
+	}
+
+	return t
 }
 
 type page struct {
-	Title                      string `yaml:"name"`
-	Slug                       string `yaml:"slug"`
-	Width                      string `yaml:"width"`
-	ShowMobileHeader           bool   `yaml:"show-mobile-header"`
-	ExpandMobilePageNavigation bool   `yaml:"expand-mobile-page-navigation"`
-	HideDesktopNavigation      bool   `yaml:"hide-desktop-navigation"`
-	CenterVertically           bool   `yaml:"center-vertically"`
-	Columns                    []struct {
-		Size    string  `yaml:"size"`
-		Widgets widgets `yaml:"widgets"`
-	} `yaml:"columns"`
-	PrimaryColumnIndex int8       `yaml:"-"`
-	mu                 sync.Mutex `yaml:"-"`
+	Title                      string                 `yaml:"name"`
+	Slug                       string                 `yaml:"slug"`
+	Width                      string                 `yaml:"width"`
+	ShowMobileHeader           bool                   `yaml:"show-mobile-header"`
+	ExpandMobilePageNavigation bool                   `yaml:"expand-mobile-page-navigation"`
+	HideDesktopNavigation      bool                   `yaml:"hide-desktop-navigation"`
+	CenterVertically           bool                   `yaml:"center-vertically"`
+	TabTitleTemplate           string                 `yaml:"tab-title-template"`
+	KeyboardShortcut           string                 `yaml:"keyboard-shortcut"`
+	Section                    string                 `yaml:"section"`
+	ReloadInterval             durationField          `yaml:"reload-interval"`
+	RenderTimeout              durationField          `yaml:"render-timeout"`
+	Theme                      *pageThemeOverrides    `yaml:"theme"`
+	CustomCSSFile              string                 `yaml:"custom-css-file"`
+	CustomCSS                  template.CSS           `yaml:"custom-css"`
+	ActiveOnTimezone           string                 `yaml:"active-on-timezone"`
+	Columns                    []pageColumn           `yaml:"columns"`
+	tabTitleTemplate           *texttemplate.Template `yaml:"-"`
+	activeOnLocation           *time.Location         `yaml:"-"`
+	theme                      *themeProperties       `yaml:"-"`
+	themeStyle                 template.HTML          `yaml:"-"`
+	mu                         sync.Mutex             `yaml:"-"`
+}
+
+type pageColumn struct {
+	Size                 string         `yaml:"size"`
+	ActiveOn             string         `yaml:"active-on"`
+	WidgetBorderColor    *hslColorField `yaml:"widget-border-color"`
+	WidgetListStyle      string         `yaml:"widget-list-style"`
+	WidgetPadding        string         `yaml:"widget-padding-override"`
+	WidgetTitleTag       string         `yaml:"widget-title-tag"`
+	WidgetDivider        bool           `yaml:"widget-divider"`
+	WidgetFocusVisible   bool           `yaml:"widget-focus-visible"`
+	WidgetImageMaxHeight string         `yaml:"widget-image-max-height"`
+	Widgets              widgets        `yaml:"widgets"`
+}
+
+// The values of a column's active-on, columns without one are active every day
+const (
+	columnActiveOnWeekdays = "weekdays"
+	columnActiveOnWeekends = "weekends"
+)
+
+// Checked separately when validating the columns of a page, since
+// each of them has to result in a valid layout by itself
+var columnDayTypes = []string{columnActiveOnWeekdays, columnActiveOnWeekends}
+
+func (c *pageColumn) isActiveOn(dayType string) bool {
+	return c.ActiveOn == "" || c.ActiveOn == dayType
+}
+
+// Uses the server's local timezone unless the page has an active-on-timezone
+func (p *page) dayType(now time.Time) string {
+	if p.activeOnLocation != nil {
+		now = now.In(p.activeOnLocation)
+	} else {
+		now = now.Local()
+	}
+
+	if weekday := now.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return columnActiveOnWeekends
+	}
+
+	return columnActiveOnWeekdays
+}
+
+// The columns that get shown at the given time, which depend on their active-on
+func (p *page) activeColumns(now time.Time) []*pageColumn {
+	dayType := p.dayType(now)
+	columns := make([]*pageColumn, 0, len(p.Columns))
+
+	for c := range p.Columns {
+		if p.Columns[c].isActiveOn(dayType) {
+			columns = append(columns, &p.Columns[c])
+		}
+	}
+
+	return columns
+}
+
+// The column that's shown first on mobile, which is the first full column
+func primaryColumnIndex(columns []*pageColumn) int {
+	for i, column := range columns {
+		if column.Size == "full" {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// Used in templates for the meta refresh tag, which only accepts seconds
+func (p *page) ReloadIntervalSeconds() int {
+	return int(time.Duration(p.ReloadInterval).Seconds())
 }
 
 func newConfigFromYAML(contents []byte) (*config, error) {
-	contents, err := parseConfigEnvVariables(contents)
+	// has to be known before the variables get replaced, so it can't come from a variable itself
+	demoMode := isDemoModeEnabled(contents)
+
+	mode := configVarModeResolve
+	if demoMode {
+		mode = configVarModeDemo
+	}
+
+	unresolvedContents := contents
+
+	contents, err := parseConfigVariables(contents, mode)
 	if err != nil {
 		return nil, err
 	}
 
+	// checked before the rest of the config so that a warning about an outdated
+	// config gets shown even if decoding it fails because of outdated options
+	meta := struct {
+		Version *int `yaml:"config-version"`
+		Server  struct {
+			Strict bool `yaml:"strict"`
+		} `yaml:"server"`
+	}{}
+
+	if err := yaml.Unmarshal(contents, &meta); err == nil {
+		if meta.Version == nil {
+			warnAboutConfigVersionMismatch(1)
+		} else if *meta.Version < 1 {
+			return nil, fmt.Errorf("config-version must be a positive number")
+		} else {
+			warnAboutConfigVersionMismatch(*meta.Version)
+		}
+	}
+
 	config := &config{}
-	config.Server.Port = 8080
 
-	err = yaml.Unmarshal(contents, config)
-	if err != nil {
+	if meta.Server.Strict {
+		if err = findUnknownConfigFields(contents); err != nil {
+			return nil, err
+		}
+
+		// catches anything that findUnknownConfigFields doesn't know how to check
+		decoder := yaml.NewDecoder(bytes.NewReader(contents))
+		decoder.KnownFields(true)
+
+		if err = decoder.Decode(config); err != nil {
+			return nil, withHSLColorErrorPath(contents, err)
+		}
+	} else {
+		err = yaml.Unmarshal(contents, config)
+		if err != nil {
+			return nil, withHSLColorErrorPath(contents, err)
+		}
+	}
+
+	if config.Version == 0 {
+		config.Version = 1
+	}
+
+	if err = resolveWidgetDefinitions(config); err != nil {
 		return nil, err
 	}
 
+	if config.Server.DemoMode && !demoMode {
+		return nil, fmt.Errorf("server: demo-mode must be set to true directly rather than through a variable")
+	}
+
+	if demoMode {
+		metricsToken, debugConfigToken := tokensUsingConfigVariables(unresolvedContents)
+
+		if config.Server.Metrics.Enabled && metricsToken {
+			return nil, fmt.Errorf("server: metrics token cannot use a variable while demo-mode is enabled since it would be replaced with a publicly known value")
+		}
+
+		if config.Server.DebugConfig.Enabled && debugConfigToken {
+			return nil, fmt.Errorf("server: debug-config token cannot use a variable while demo-mode is enabled since it would be replaced with a publicly known value")
+		}
+	}
+
+	assignWidgetStableIDs(config)
+
 	if err = isConfigStateValid(config); err != nil {
 		return nil, err
 	}
 
+	if config.Server.DebugConfig.Enabled {
+		// redacted from the contents as they were before the variables got replaced, since
+		// that's the only point at which it's known which of the values came from variables
+		debugMode := configVarModeRedactSecrets
+		if demoMode {
+			debugMode = configVarModeDemo
+		}
+
+		config.debugContents, err = redactConfigForDebugging(unresolvedContents, debugMode)
+		if err != nil {
+			return nil, fmt.Errorf("server: debug-config: %w", err)
+		}
+	}
+
+	if config.Server.Socket == "" && !config.Server.Port.isSet {
+		config.Server.Port.Number = 8080
+	}
+
+	if config.Server.Socket != "" && config.Server.SocketMode == "" {
+		config.Server.SocketMode = "660"
+	}
+
+	// the same option under the name it was first requested with
+	if config.Server.WidgetRefreshJitter != 0 {
+		if config.Server.FetchJitter != 0 {
+			return nil, errors.New("server: fetch-jitter and widget-refresh-jitter are the same option, only one of them can be set")
+		}
+
+		config.Server.FetchJitter = config.Server.WidgetRefreshJitter
+	}
+
+	for p := range config.Pages {
+		page := &config.Pages[p]
+
+		if page.Slug == "" {
+			page.Slug = titleToSlug(page.Title)
+		}
+
+		if page.ActiveOnTimezone != "" {
+			page.activeOnLocation, err = time.LoadLocation(page.ActiveOnTimezone)
+			if err != nil {
+				return nil, fmt.Errorf("page %d: invalid active-on-timezone %s, must be a name such as Europe/London", p+1, page.ActiveOnTimezone)
+			}
+		}
+
+		if page.TabTitleTemplate == "" {
+			page.TabTitleTemplate = config.Document.TabTitleTemplate
+		}
+
+		if page.TabTitleTemplate == "" {
+			continue
+		}
+
+		page.tabTitleTemplate, err = texttemplate.New("tab-title").Parse(page.TabTitleTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: parsing tab-title-template: %v", p+1, err)
+		}
+	}
+
+	config.buildPageIndex()
+
+	config.experimentalFeatures = make(map[string]bool, len(config.Server.ExperimentalFeatures))
+	for _, feature := range config.Server.ExperimentalFeatures {
+		config.experimentalFeatures[feature] = true
+	}
+
+	baseURL := strings.TrimRight(config.Server.BaseURL, "/")
+	prefixed := make(map[widget]struct{})
+	for p := range config.Pages {
+		for c := range config.Pages[p].Columns {
+			prefixRelativeTitleURLs(config.Pages[p].Columns[c].Widgets, baseURL, prefixed)
+		}
+	}
+
+	if err := resolvePageEmbeds(config, baseURL); err != nil {
+		return nil, err
+	}
+
+	tagged := make(map[widget]struct{})
 	for p := range config.Pages {
 		for c := range config.Pages[p].Columns {
-			for w := range config.Pages[p].Columns[c].Widgets {
-				if err := config.Pages[p].Columns[c].Widgets[w].initialize(); err != nil {
-					return nil, formatWidgetInitError(err, config.Pages[p].Columns[c].Widgets[w])
-				}
+			if tag := config.Pages[p].Columns[c].WidgetTitleTag; tag != "" {
+				applyWidgetTitleTag(config.Pages[p].Columns[c].Widgets, tag, tagged)
+			}
+		}
+	}
+
+	if config.Server.HTTPClientCertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(config.Server.HTTPClientCertFile, config.Server.HTTPClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("server: loading http-client-cert-file and http-client-key-file: %v", err)
+		}
+
+		config.Server.httpClientCertificate = &certificate
+	}
+
+	config.Server.Locale = normalizeLocale(config.Server.Locale)
+	if config.Server.Locale != "" {
+		hourFormat := localeHourFormat(config.Server.Locale)
+		for p := range config.Pages {
+			for c := range config.Pages[p].Columns {
+				applyLocaleHourFormat(config.Pages[p].Columns[c].Widgets, hourFormat)
 			}
 		}
 	}
 
+	if err := initializeWidgets(config.Pages); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
-// TODO: change the pattern so that it doesn't match commented out lines
-var configEnvVariablePattern = regexp.MustCompile(`(^|.)\$\{([A-Z0-9_]+)\}`)
+// Initializes the widgets of all pages concurrently, since some of them read files or compile
+// templates, and reports every widget that failed rather than only the first one so that
+// they can all be fixed in one go. Widgets within containers get initialized by the container.
+func initializeWidgets(pages []page) error {
+	type widgetPosition struct {
+		page, column, widget int
+	}
 
-func parseConfigEnvVariables(contents []byte) ([]byte, error) {
-	var err error
+	var positions []widgetPosition
+	for p := range pages {
+		for c := range pages[p].Columns {
+			for w := range pages[p].Columns[c].Widgets {
+				positions = append(positions, widgetPosition{p, c, w})
+			}
+		}
+	}
+
+	errs := make([]error, len(positions))
+	var wg sync.WaitGroup
+
+	for i, pos := range positions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			widget := pages[pos.page].Columns[pos.column].Widgets[pos.widget]
+			if err := widget.initialize(); err != nil {
+				errs[i] = fmt.Errorf(
+					"page %d (%s), column %d, widget %d: %w",
+					pos.page+1, pages[pos.page].Title, pos.column+1, pos.widget+1, formatWidgetInitError(err, widget),
+				)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+
+	switch len(failed) {
+	case 0:
+		return nil
+	case 1:
+		return failed[0]
+	default:
+		return fmt.Errorf("%d widgets failed to initialize:\n%w", len(failed), errors.Join(failed...))
+	}
+}
+
+// Clone returns a deep copy of the config which can be inspected or compared without
+// holding on to the live one. Widgets hold runtime state (cached data, schedules, their
+// own locks) and are shared between the original and the copy rather than duplicated,
+// as are parsed templates, locations and certificates which never change once loaded.
+func (c *config) Clone() *config {
+	clone := &config{
+		Server:      c.Server,
+		Document:    c.Document,
+		Theme:       c.Theme.clone(),
+		Branding:    c.Branding,
+		Version:     c.Version,
+		Definitions: make(widgetDefinitions, len(c.Definitions)),
+		Pages:       make([]page, len(c.Pages)),
+	}
+
+	clone.Server.AssetsPath = slices.Clone(c.Server.AssetsPath)
+	clone.Server.DisableRoutes = slices.Clone(c.Server.DisableRoutes)
+	clone.Server.NoProxy = slices.Clone(c.Server.NoProxy)
+	clone.Server.WatchEnvVars = slices.Clone(c.Server.WatchEnvVars)
+	clone.Server.Headers = maps.Clone(c.Server.Headers)
+	clone.Server.ExperimentalFeatures = slices.Clone(c.Server.ExperimentalFeatures)
+
+	if c.Server.UserAgent != nil {
+		userAgent := *c.Server.UserAgent
+		clone.Server.UserAgent = &userAgent
+	}
+
+	for id, node := range c.Definitions {
+		clone.Definitions[id] = *cloneYAMLNode(&node, make(map[*yaml.Node]*yaml.Node))
+	}
+
+	for i := range c.Pages {
+		src, dst := &c.Pages[i], &clone.Pages[i]
+
+		// fields are copied one by one because page contains a mutex
+		dst.Title = src.Title
+		dst.Slug = src.Slug
+		dst.Width = src.Width
+		dst.ShowMobileHeader = src.ShowMobileHeader
+		dst.ExpandMobilePageNavigation = src.ExpandMobilePageNavigation
+		dst.HideDesktopNavigation = src.HideDesktopNavigation
+		dst.CenterVertically = src.CenterVertically
+		dst.TabTitleTemplate = src.TabTitleTemplate
+		dst.KeyboardShortcut = src.KeyboardShortcut
+		dst.Section = src.Section
+		dst.ReloadInterval = src.ReloadInterval
+		dst.RenderTimeout = src.RenderTimeout
+		dst.Theme = src.Theme.clone()
+		dst.CustomCSSFile = src.CustomCSSFile
+		dst.CustomCSS = src.CustomCSS
+		dst.ActiveOnTimezone = src.ActiveOnTimezone
+		dst.Columns = slices.Clone(src.Columns)
+		dst.tabTitleTemplate = src.tabTitleTemplate
+		dst.activeOnLocation = src.activeOnLocation
+		dst.themeStyle = src.themeStyle
+
+		// pages without theme overrides point to the theme of the config itself
+		if src.theme == &c.Theme {
+			dst.theme = &clone.Theme
+		} else if src.theme != nil {
+			theme := src.theme.clone()
+			dst.theme = &theme
+		}
+
+		for j := range dst.Columns {
+			dst.Columns[j].WidgetBorderColor = src.Columns[j].WidgetBorderColor.clone()
+			dst.Columns[j].Widgets = slices.Clone(src.Columns[j].Widgets)
+		}
+	}
+
+	clone.experimentalFeatures = maps.Clone(c.experimentalFeatures)
+	clone.debugContents = slices.Clone(c.debugContents)
+	clone.buildPageIndex()
+
+	return clone
+}
+
+func (t themeProperties) clone() themeProperties {
+	t.BackgroundColor = t.BackgroundColor.clone()
+	t.PrimaryColor = t.PrimaryColor.clone()
+	t.PositiveColor = t.PositiveColor.clone()
+	t.NegativeColor = t.NegativeColor.clone()
+	t.HighlightColor = t.HighlightColor.clone()
+
+	return t
+}
+
+// Aliases point to nodes elsewhere within the same tree, so nodes which were already
+// copied are looked up to keep pointing to the copy rather than to the original
+func cloneYAMLNode(node *yaml.Node, cloned map[*yaml.Node]*yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+
+	if clone, ok := cloned[node]; ok {
+		return clone
+	}
+
+	clone := *node
+	cloned[node] = &clone
+
+	clone.Content = make([]*yaml.Node, len(node.Content))
+	for i := range node.Content {
+		clone.Content[i] = cloneYAMLNode(node.Content[i], cloned)
+	}
+
+	if node.Content == nil {
+		clone.Content = nil
+	}
+
+	clone.Alias = cloneYAMLNode(node.Alias, cloned)
+
+	return &clone
+}
+
+// The first page is also indexed under an empty slug so that it gets served at the root
+func (c *config) buildPageIndex() {
+	c.pageIndex = make(map[string]*page, len(c.Pages)+1)
+
+	if len(c.Pages) > 0 {
+		c.pageIndex[""] = &c.Pages[0]
+	}
+
+	for p := range c.Pages {
+		c.pageIndex[c.Pages[p].Slug] = &c.Pages[p]
+	}
+}
+
+func (c *config) PageBySlug(slug string) (*page, bool) {
+	page, exists := c.pageIndex[slug]
+	return page, exists
+}
+
+// Experimental features which can be opted into through server.experimental-features,
+// code paths specific to a feature should check featureEnabled before running
+var experimentalFeatures = []string{}
+
+func (c *config) featureEnabled(name string) bool {
+	return c.experimentalFeatures[name]
+}
+
+// TODO: don't replace variables within commented out lines
+// Variables are written as ${KEY} as well as ${type:KEY}, where multiple types can be chained
+// like ${type1:type2:KEY} and get applied from right to left, see parseConfigVariables
+
+var envVarNamePattern = regexp.MustCompile(`^[A-Z0-9_]+$`)
+
+const (
+	configVarTypeBase64        = "base64"
+	configVarTypeJSONStringify = "json-stringify"
+	configVarTypeHex           = "hex"
+	// the variable contains the name of another variable that has the value, ie ${envFromEnv:SECRET_VAR_NAME}
+	configVarTypeEnvFromEnv = "env-from-env"
+	// uses the key as the length rather than the name of a variable, ie ${random:32}
+	configVarTypeRandom = "random"
+	// reads the key from a credential store rather than a variable, ie ${smtp-password:pass:email/smtp}
+	configVarTypeSMTPPassword = "smtp-password"
+)
+
+// Other spellings of types, which get replaced with the type they refer to when parsed
+var configVarTypeAliases = map[string]string{
+	"envFromEnv": configVarTypeEnvFromEnv,
+}
+
+const maxRandomConfigValueLength = 256
+
+type configVarMode int
+
+const (
+	configVarModeResolve configVarMode = iota
+	// every value gets replaced with demoModeRedactedValue
+	configVarModeDemo
+	// every value gets replaced with configRedactedValue, used for showing the config through the debug endpoint
+	configVarModeRedactSecrets
+)
+
+// Replaces the values of environment variables in demo mode, so that
+// a config which gets shown or leaked doesn't expose real secrets
+const demoModeRedactedValue = "redacted"
+
+func isDemoModeEnabled(contents []byte) bool {
+	meta := struct {
+		Server struct {
+			DemoMode bool `yaml:"demo-mode"`
+		} `yaml:"server"`
+	}{}
+
+	if err := yaml.Unmarshal(contents, &meta); err != nil {
+		return false
+	}
+
+	return meta.Server.DemoMode
+}
+
+// Whether the tokens of metrics and debug-config use a variable anywhere within them, checked
+// before the variables get replaced since in demo mode even a token such as Bearer ${TOKEN}
+// ends up being publicly known
+func tokensUsingConfigVariables(unresolvedContents []byte) (metrics, debugConfig bool) {
+	meta := struct {
+		Server struct {
+			Metrics struct {
+				Token string `yaml:"token"`
+			} `yaml:"metrics"`
+			DebugConfig struct {
+				Token string `yaml:"token"`
+			} `yaml:"debug-config"`
+		} `yaml:"server"`
+	}{}
+
+	// assumes the worst when it can't be told which parts come from variables
+	if err := yaml.Unmarshal(unresolvedContents, &meta); err != nil {
+		return true, true
+	}
+
+	usesVariable := func(value string) bool {
+		replaced, err := parseConfigVariables([]byte(value), configVarModeDemo)
+		return err != nil || string(replaced) != value
+	}
+
+	return usesVariable(meta.Server.Metrics.Token), usesVariable(meta.Server.DebugConfig.Token)
+}
+
+// Random values are generated once per length and kept for as long as the process
+// runs so that reloading the config doesn't change them
+var randomConfigValues = struct {
+	mu     sync.Mutex
+	values map[int]string
+}{values: make(map[int]string)}
+
+func randomConfigValue(length int) string {
+	randomConfigValues.mu.Lock()
+	defer randomConfigValues.mu.Unlock()
+
+	if value, ok := randomConfigValues.values[length]; ok {
+		return value
+	}
+
+	b := make([]byte, (length+1)/2)
+	// never returns an error, see https://pkg.go.dev/crypto/rand#Read
+	rand.Read(b)
+	value := hex.EncodeToString(b)[:length]
+	randomConfigValues.values[length] = value
+
+	return value
+}
+
+func parseConfigVariables(contents []byte, mode configVarMode) ([]byte, error) {
+	var replaced bytes.Buffer
+	replaced.Grow(len(contents))
+
+	for i := 0; i < len(contents); {
+		escaped := contents[i] == '\\' && i+1 < len(contents) && contents[i+1] == '$'
+		start := i
+		if escaped {
+			start++
+		}
+
+		if contents[start] != '$' {
+			replaced.WriteByte(contents[i])
+			i++
+			continue
+		}
+
+		types, key, length, ok := parseConfigVariableReference(contents[start:])
+		if !ok {
+			// not a variable, but what comes after the $ may still contain one, as in ${NOT_${VALID}}
+			replaced.Write(contents[i : start+1])
+			i = start + 1
+			continue
+		}
+
+		reference := contents[start : start+length]
+		i = start + length
+
+		if escaped {
+			replaced.Write(reference)
+			continue
+		}
+
+		value, err := resolveConfigVariable(types, key, mode)
+		if err != nil {
+			return nil, err
+		}
+
+		replaced.WriteString(value)
+	}
+
+	return replaced.Bytes(), nil
+}
+
+const (
+	configVarStateStart = iota
+	configVarStateSegment
+	configVarStateSeparator
+)
+
+// Parses a ${KEY} or ${type1:type2:KEY} reference at the start of the given input one
+// character at a time, returning how long it is. Types can only contain letters, digits
+// and dashes and can't start with an uppercase letter, while keys can only contain uppercase
+// letters, digits and underscores, anything else means that the input doesn't start with a reference.
+func parseConfigVariableReference(input []byte) (types []string, key string, length int, ok bool) {
+	if len(input) < 4 || input[0] != '$' || input[1] != '{' {
+		return nil, "", 0, false
+	}
+
+	state := configVarStateStart
+	segmentStart := 2
+	isValidType, isValidKey := true, true
+
+	for i := 2; i < len(input); i++ {
+		c := input[i]
+
+		switch {
+		case c == '}':
+			if state != configVarStateSegment || !isValidKey {
+				return nil, "", 0, false
+			}
+
+			return types, string(input[segmentStart:i]), i + 1, true
+		case c == ':':
+			if state != configVarStateSegment || !isValidType {
+				return nil, "", 0, false
+			}
+
+			typ := string(input[segmentStart:i])
+			if alias, ok := configVarTypeAliases[typ]; ok {
+				typ = alias
+			}
+
+			types = append(types, typ)
+			state = configVarStateSeparator
+
+			if types[len(types)-1] == configVarTypeSMTPPassword {
+				store, key, length, ok := parseCredentialReference(input[i+1:])
+				if !ok {
+					return nil, "", 0, false
+				}
+
+				return append(types, store), key, i + 1 + length, true
+			}
+		case isConfigVarTypeChar(c, false) || isConfigVarKeyChar(c):
+			isFirst := state != configVarStateSegment
+			if isFirst {
+				state = configVarStateSegment
+				segmentStart = i
+				isValidType, isValidKey = true, true
+			}
+
+			isValidType = isValidType && isConfigVarTypeChar(c, isFirst)
+			isValidKey = isValidKey && isConfigVarKeyChar(c)
+		default:
+			return nil, "", 0, false
+		}
+	}
+
+	return nil, "", 0, false
+}
+
+// Uppercase letters are allowed after the first character for camel cased types such as envFromEnv,
+// while types starting with one would be indistinguishable from keys
+func isConfigVarTypeChar(c byte, isFirst bool) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || (!isFirst && c >= 'A' && c <= 'Z')
+}
+
+func isConfigVarKeyChar(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_'
+}
+
+// Types get applied from right to left, so ${base64:json-stringify:KEY} is the same as base64(json-stringify(KEY))
+func resolveConfigVariable(types []string, key string, mode configVarMode) (string, error) {
+	var value string
+
+	if mode == configVarModeRedactSecrets {
+		if len(types) > 0 && types[0] == configVarTypeJSONStringify {
+			return `"` + configRedactedValue + `"`, nil
+		}
+
+		return configRedactedValue, nil
+	}
+
+	if len(types) > 0 && types[len(types)-1] == configVarTypeRandom {
+		length, err := strconv.Atoi(key)
+		if err != nil || length < 1 || length > maxRandomConfigValueLength {
+			return "", fmt.Errorf("length of random config variable must be a number between 1 and %d, got %s", maxRandomConfigValueLength, key)
+		}
+
+		value = randomConfigValue(length)
+		types = types[:len(types)-1]
+	} else if mode == configVarModeDemo {
+		// the types are skipped since the placeholder isn't in the format
+		// that most of them expect, other than still quoting it for JSON
+		if len(types) > 0 && types[0] == configVarTypeJSONStringify {
+			return `"` + demoModeRedactedValue + `"`, nil
+		}
+
+		return demoModeRedactedValue, nil
+	} else if len(types) > 1 && types[len(types)-2] == configVarTypeSMTPPassword {
+		var err error
+		value, err = readFromCredentialStore(types[len(types)-1], key)
+		if err != nil {
+			return "", err
+		}
+
+		types = types[:len(types)-2]
+	} else {
+		var found bool
+		value, found = os.LookupEnv(key)
+		if !found {
+			if len(types) > 0 && types[len(types)-1] == configVarTypeEnvFromEnv {
+				return "", fmt.Errorf("environment variable %s not found, it should contain the name of the variable to read with envFromEnv", key)
+			}
+
+			return "", fmt.Errorf("environment variable %s not found", key)
+		}
+	}
+
+	for i := len(types) - 1; i >= 0; i-- {
+		var err error
+		value, err = parseConfigVariableOfType(types[i], key, value)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return value, nil
+}
+
+// Transforms the value of the variable with the given key according to the type.
+// Errors must not include the value since it may contain secrets.
+func parseConfigVariableOfType(variableType, key, value string) (string, error) {
+	switch variableType {
+	case configVarTypeBase64:
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("environment variable %s does not contain valid base64", key)
+		}
+
+		return string(decoded), nil
+	case configVarTypeJSONStringify:
+		// marshaling a string can't fail
+		encoded, _ := json.Marshal(value)
+		return string(encoded), nil
+	case configVarTypeHex:
+		color, err := parseHSLColor(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("environment variable %s does not contain a valid HSL color: %v", key, err)
+		}
+
+		return color.toHex(), nil
+	case configVarTypeEnvFromEnv:
+		// the value is the name of a variable rather than a secret, so it's fine to include it
+		name := strings.TrimSpace(value)
+		if name == "" {
+			return "", fmt.Errorf("environment variable %s is empty, it should contain the name of the variable to read with %s", key, variableType)
+		}
+
+		inner, found := os.LookupEnv(name)
+		if !found {
+			return "", fmt.Errorf("environment variable %s not found, its name comes from environment variable %s", name, key)
+		}
+
+		return inner, nil
+	default:
+		return "", fmt.Errorf("unknown type %s for config variable %s", variableType, key)
+	}
+}
+
+func formatWidgetInitError(err error, w widget) error {
+	return fmt.Errorf("%s widget: %v", w.GetType(), err)
+}
+
+// Also matches `!include-once:`, which is skipped if the file has already been included
+var includePattern = regexp.MustCompile(`(?m)^(\s*)!include(-once)?:\s*(.+)$`)
+
+var (
+	includeIfPattern          = regexp.MustCompile(`(?m)^(\s*)!include-if:[ \t]*(\S+)[ \t]+(.+)$`)
+	includeIfConditionPattern = regexp.MustCompile(`^\$\{([A-Z0-9_]+)\}(==|!=)(.*)$`)
+)
+
+// Turns each `!include-if: ${NAME}==value path` into a regular include when the condition
+// holds and removes it otherwise, unset variables are treated as being empty
+func resolveConditionalIncludes(contents []byte) ([]byte, error) {
+	var err error
+
+	contents = includeIfPattern.ReplaceAllFunc(contents, func(match []byte) []byte {
+		if err != nil {
+			return nil
+		}
+
+		matches := includeIfPattern.FindSubmatch(match)
+		indent, condition, path := string(matches[1]), string(matches[2]), strings.TrimSpace(string(matches[3]))
+
+		conditionMatches := includeIfConditionPattern.FindStringSubmatch(condition)
+		if conditionMatches == nil {
+			err = fmt.Errorf(
+				"invalid include-if condition %s, expected ${NAME}==value or ${NAME}!=value with no spaces, followed by the path to include",
+				condition,
+			)
+			return nil
+		}
+
+		key, operator, expected := conditionMatches[1], conditionMatches[2], conditionMatches[3]
+		if (os.Getenv(key) == expected) != (operator == "==") {
+			return nil
+		}
+
+		return []byte(indent + "!include: " + path)
+	})
+
+	return contents, err
+}
+
+var includeIfExistsPattern = regexp.MustCompile(`(?m)^(\s*)!include-if-exists:\s*(.+)$`)
+
+// Turns each `!include-if-exists: path` into a regular include if the file exists and
+// removes it otherwise. Errors other than the file not existing are still reported.
+func resolveOptionalIncludes(contents []byte, baseDir string) ([]byte, error) {
+	var err error
+
+	contents = includeIfExistsPattern.ReplaceAllFunc(contents, func(match []byte) []byte {
+		if err != nil {
+			return nil
+		}
+
+		matches := includeIfExistsPattern.FindSubmatch(match)
+		indent, path := string(matches[1]), strings.TrimSpace(string(matches[2]))
+
+		var absPath string
+		absPath, err = expandIncludePath(path)
+		if err != nil {
+			return nil
+		}
+
+		if !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(baseDir, absPath)
+		}
+
+		if _, statErr := os.Stat(absPath); statErr != nil {
+			if !errors.Is(statErr, fs.ErrNotExist) {
+				err = fmt.Errorf("checking whether included file %s exists: %w", absPath, statErr)
+				return nil
+			}
+
+			slog.Debug("Skipping include of file that does not exist", "path", absPath)
+			return nil
+		}
+
+		return []byte(indent + "!include: " + path)
+	})
+
+	return contents, err
+}
+
+var includeDirPattern = regexp.MustCompile(`(?m)^(\s*)!include-dir:\s*(.+)$`)
+
+// Lists the names of the files in an included directory that go first, one per line
+const includeDirOrderFileName = ".order"
+
+// Turns each `!include-dir: path` into a regular include of every YAML file in the directory,
+// with the ones listed in its .order file going first and the rest following alphabetically.
+// Each directory is passed to onDir so that it can be watched for files being added to it.
+func resolveDirectoryIncludes(contents []byte, baseDir string, onDir func(string)) ([]byte, error) {
+	var err error
+
+	contents = includeDirPattern.ReplaceAllFunc(contents, func(match []byte) []byte {
+		if err != nil {
+			return nil
+		}
+
+		matches := includeDirPattern.FindSubmatch(match)
+		indent, path := string(matches[1]), strings.TrimSpace(string(matches[2]))
+
+		var dirPath string
+		dirPath, err = expandIncludePath(path)
+		if err != nil {
+			return nil
+		}
+
+		if !filepath.IsAbs(dirPath) {
+			dirPath = filepath.Join(baseDir, dirPath)
+		}
+		dirPath = filepath.Clean(dirPath)
+
+		var files []string
+		files, err = listIncludedDirectoryFiles(dirPath)
+		if err != nil {
+			return nil
+		}
+
+		onDir(dirPath)
+
+		var result strings.Builder
+		for i, file := range files {
+			if i > 0 {
+				result.WriteString("\n")
+			}
+
+			result.WriteString(indent + "!include: " + filepath.Join(dirPath, file))
+		}
+
+		return []byte(result.String())
+	})
+
+	return contents, err
+}
+
+func listIncludedDirectoryFiles(dirPath string) ([]string, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("included directory %s does not exist", dirPath)
+		}
+
+		return nil, fmt.Errorf("reading included directory %s: %w", dirPath, err)
+	}
+
+	var files []string
+	listed := make(map[string]struct{})
+
+	orderContents, err := os.ReadFile(filepath.Join(dirPath, includeDirOrderFileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("reading %s of included directory %s: %w", includeDirOrderFileName, dirPath, err)
+	}
+
+	for _, line := range strings.Split(string(orderContents), "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+
+		if _, seen := listed[name]; seen {
+			return nil, fmt.Errorf("%s of included directory %s lists %s more than once", includeDirOrderFileName, dirPath, name)
+		}
+
+		if filepath.Base(name) != name {
+			return nil, fmt.Errorf("%s of included directory %s can only list file names, got %s", includeDirOrderFileName, dirPath, name)
+		}
+
+		if info, err := os.Stat(filepath.Join(dirPath, name)); err != nil || !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("%s of included directory %s lists %s, which is not a file in the directory", includeDirOrderFileName, dirPath, name)
+		}
+
+		listed[name] = struct{}{}
+		files = append(files, name)
+	}
+
+	// entries are already sorted by name
+	for _, entry := range entries {
+		name := entry.Name()
+		if _, ok := listed[name]; ok || !entry.Type().IsRegular() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if ext := filepath.Ext(name); ext == ".yml" || ext == ".yaml" {
+			files = append(files, name)
+		}
+	}
+
+	return files, nil
+}
+
+// Replaces the config variables within the path of an include, as in `!include: ${CONFIG_DIR}/widgets.yml`
+func expandIncludePath(path string) (string, error) {
+	expanded, err := parseConfigVariables([]byte(path), configVarModeResolve)
+	if err != nil {
+		return "", fmt.Errorf("resolving variables in include path %s: %w", path, err)
+	}
+
+	return string(expanded), nil
+}
+
+var includeRawPattern = regexp.MustCompile(`(?m)^([ \t]*(?:- )?(?:[^\s#:-][^#:\n]*:[ \t]+)?)!include-raw:[ \t]*(.+)$`)
+
+// Replaces each `!include-raw: path` with the contents of the file as a single string value. Unlike
+// the other includes, the contents aren't treated as YAML, so the file doesn't need to be indented
+// to match where it's included and gets used exactly as it is, config variables included.
+func resolveRawIncludes(contents []byte, readIncludedFile func(path string) ([]byte, string, error)) ([]byte, error) {
+	var err error
+
+	contents = includeRawPattern.ReplaceAllFunc(contents, func(match []byte) []byte {
+		if err != nil {
+			return nil
+		}
+
+		matches := includeRawPattern.FindSubmatch(match)
+		prefix := string(matches[1])
+
+		var fileContents []byte
+		var path string
+		fileContents, path, err = readIncludedFile(strings.TrimSpace(string(matches[2])))
+		if err != nil {
+			return nil
+		}
+
+		if !utf8.Valid(fileContents) {
+			err = fmt.Errorf("raw included file %s is not valid UTF-8 text", path)
+			return nil
+		}
+
+		return []byte(prefix + quoteRawIncludeContents(fileContents))
+	})
+
+	return contents, err
+}
+
+// Quoted rather than inserted as a block scalar so that the contents stay the same no matter
+// what they start with or contain. Dollar signs are escaped so that they're not seen as the
+// start of config variables, which get replaced after all includes have been resolved.
+func quoteRawIncludeContents(contents []byte) string {
+	return strings.ReplaceAll(strconv.Quote(string(contents)), "$", `\x24`)
+}
+
+const (
+	defaultConfigIncludedFilesLimit = 100
+	defaultConfigTotalSizeLimit     = 5 * 1024 * 1024
+)
+
+// Reads a positive integer limit from the environment, falling back to def if unset
+func configLimitFromEnv(name string, def int) (int, error) {
+	value, found := os.LookupEnv(name)
+	if !found {
+		return def, nil
+	}
+
+	limit, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", name, value)
+	}
+
+	return limit, nil
+}
+
+func parseYAMLIncludes(mainFilePath string) ([]byte, map[string]struct{}, error) {
+	includedFilesLimit, err := configLimitFromEnv("GLANCE_CONFIG_MAX_INCLUDED_FILES", defaultConfigIncludedFilesLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totalSizeLimit, err := configLimitFromEnv("GLANCE_CONFIG_MAX_SIZE", defaultConfigTotalSizeLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mainFileContents, err := os.ReadFile(mainFilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading main YAML file: %w", err)
+	}
+
+	totalSize := len(mainFileContents)
+	if totalSize > totalSizeLimit {
+		return nil, nil, fmt.Errorf("main YAML file %s exceeds the maximum config size of %d bytes", mainFilePath, totalSizeLimit)
+	}
+
+	mainFileAbsPath, err := filepath.Abs(mainFilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting absolute path of main YAML file: %w", err)
+	}
+	mainFileDir := filepath.Dir(mainFileAbsPath)
+
+	mainFileContents, err = resolveConditionalIncludes(mainFileContents)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mainFileContents, err = resolveOptionalIncludes(mainFileContents, mainFileDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	includes := make(map[string]struct{})
+
+	// the directories are watched alongside the files so that adding a file to one triggers a reload
+	mainFileContents, err = resolveDirectoryIncludes(mainFileContents, mainFileDir, func(dirPath string) {
+		includes[dirPath] = struct{}{}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	absIncludePath := func(includeFilePath string) string {
+		if !filepath.IsAbs(includeFilePath) {
+			return filepath.Join(mainFileDir, includeFilePath)
+		}
+
+		return filepath.Clean(includeFilePath)
+	}
+
+	// the included directories are only in includes so that they get watched, so
+	// the files get counted separately and only they count towards the limit
+	includedFiles := 0
+
+	readIncludedFileAtPath := func(includeFilePath string) ([]byte, string, error) {
+		_, seen := includes[includeFilePath]
+		if !seen && includedFiles >= includedFilesLimit {
+			return nil, "", fmt.Errorf(
+				"including file %s exceeds the maximum number of included files (%d)",
+				includeFilePath, includedFilesLimit,
+			)
+		}
+
+		fileContents, err := os.ReadFile(includeFilePath)
+		if err != nil {
+			return nil, "", fmt.Errorf("reading included file %s: %w", includeFilePath, err)
+		}
+
+		totalSize += len(fileContents)
+		if totalSize > totalSizeLimit {
+			return nil, "", fmt.Errorf(
+				"including file %s exceeds the maximum config size of %d bytes",
+				includeFilePath, totalSizeLimit,
+			)
+		}
+
+		if !seen {
+			includedFiles++
+		}
+
+		includes[includeFilePath] = struct{}{}
+		return fileContents, includeFilePath, nil
+	}
+
+	readIncludedFile := func(path string) ([]byte, string, error) {
+		expanded, err := expandIncludePath(path)
+		if err != nil {
+			return nil, "", err
+		}
+
+		fileContents, includeFilePath, err := readIncludedFileAtPath(absIncludePath(expanded))
+		// mention the path as it was written so that it's clear which variable led to the file
+		if err != nil && expanded != path {
+			return nil, "", fmt.Errorf("%w (include path %s)", err, path)
+		}
+
+		return fileContents, includeFilePath, err
+	}
+
+	// the files whose includes are being resolved, used to catch files which end up including themselves
+	var includeChain []string
+
+	// included files get their own includes resolved as soon as they're included, so the
+	// includes are resolved from the top of the resulting config to the bottom
+	var resolveIncludeDirectives func(contents []byte) ([]byte, error)
+	resolveIncludeDirectives = func(contents []byte) ([]byte, error) {
+		var err error
+
+		contents = includePattern.ReplaceAllFunc(contents, func(match []byte) []byte {
+			if err != nil {
+				return nil
+			}
+
+			matches := includePattern.FindSubmatch(match)
+			if len(matches) != 4 {
+				err = fmt.Errorf("invalid include match: %v", matches)
+				return nil
+			}
+
+			indent, once, path := string(matches[1]), len(matches[2]) > 0, strings.TrimSpace(string(matches[3]))
+
+			// only the first time the file is included, from whichever file it
+			// is along the way, gets its contents
+			if once {
+				var expanded string
+				expanded, err = expandIncludePath(path)
+				if err != nil {
+					return nil
+				}
+
+				if _, included := includes[absIncludePath(expanded)]; included {
+					slog.Debug("Skipping include-once of file that has already been included", "path", absIncludePath(expanded))
+					return nil
+				}
+			}
+
+			var fileContents []byte
+			var includeFilePath string
+			fileContents, includeFilePath, err = readIncludedFile(path)
+			if err != nil {
+				return nil
+			}
+
+			if slices.Contains(includeChain, includeFilePath) {
+				err = fmt.Errorf("included file %s ends up including itself through %s", includeFilePath, strings.Join(includeChain, " -> "))
+				return nil
+			}
+
+			includeChain = append(includeChain, includeFilePath)
+			fileContents, err = resolveIncludeDirectives(fileContents)
+			includeChain = includeChain[:len(includeChain)-1]
+			if err != nil {
+				return nil
+			}
+
+			return []byte(prefixStringLines(indent, string(fileContents)))
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return contents, nil
+	}
+
+	resolveIncludes := func(contents []byte) ([]byte, error) {
+		contents, err := resolveIncludeDirectives(contents)
+		if err != nil {
+			return nil, err
+		}
+
+		return resolveRawIncludes(contents, readIncludedFile)
+	}
+
+	mainFileContents, mergedFiles, err := markMergeIncludes(mainFileContents, readIncludedFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mainFileContents, err = resolveIncludes(mainFileContents)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(mergedFiles) > 0 {
+		mainFileContents, err = applyMergeIncludes(mainFileContents, mergedFiles)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return mainFileContents, includes, nil
+}
+
+var includeMergePattern = regexp.MustCompile(`(?m)^(\s*)!include-merge:\s*(.+)$`)
+
+// Placeholder keys which mark where the mappings of merged files go, followed by their index
+const includeMergeMarkerPrefix = "!include-merge "
+
+// Replaces each `!include-merge: path` with a placeholder key and returns the parsed contents
+// of the files, which get merged in by applyMergeIncludes once the rest of the includes are
+// resolved since merging can only be done after parsing the whole document
+func markMergeIncludes(
+	contents []byte,
+	readIncludedFile func(path string) ([]byte, string, error),
+) ([]byte, []*yaml.Node, error) {
+	var err error
+	var mergedFiles []*yaml.Node
+
+	contents = includeMergePattern.ReplaceAllFunc(contents, func(match []byte) []byte {
+		if err != nil {
+			return nil
+		}
+
+		matches := includeMergePattern.FindSubmatch(match)
+		indent := string(matches[1])
+
+		var fileContents []byte
+		var path string
+		fileContents, path, err = readIncludedFile(strings.TrimSpace(string(matches[2])))
+		if err != nil {
+			return nil
+		}
+
+		// like any other included file, only !include-raw gets resolved within it
+		fileContents, err = resolveRawIncludes(fileContents, readIncludedFile)
+		if err != nil {
+			return nil
+		}
+
+		var document yaml.Node
+		if err = yaml.Unmarshal(fileContents, &document); err != nil {
+			err = fmt.Errorf("parsing merged file %s: %w", path, err)
+			return nil
+		}
+
+		mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if len(document.Content) > 0 {
+			if document.Content[0].Kind != yaml.MappingNode {
+				err = fmt.Errorf("merged file %s must contain a mapping at the top level", path)
+				return nil
+			}
+
+			mapping = document.Content[0]
+		}
+
+		mergedFiles = append(mergedFiles, mapping)
+		return []byte(fmt.Sprintf(`%s"%s%d":`, indent, includeMergeMarkerPrefix, len(mergedFiles)-1))
+	})
+
+	return contents, mergedFiles, err
+}
+
+// The merged document has to be encoded again since the rest of the config loading works
+// on its text, which means that it gets reformatted and that the line numbers in any later
+// errors refer to the encoded document, as shown by config:print, rather than the files
+func applyMergeIncludes(contents []byte, mergedFiles []*yaml.Node) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(contents, &root); err != nil {
+		return nil, err
+	}
+
+	replaceMergeMarkers(&root, mergedFiles)
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&root); err != nil {
+		return nil, fmt.Errorf("encoding config after merging included files: %w", err)
+	}
+
+	encoder.Close()
+	return buffer.Bytes(), nil
+}
+
+// Keys are applied in the order they appear in, so the contents of a merged file override
+// the keys before the directive and get overridden by the keys after it
+func replaceMergeMarkers(node *yaml.Node, mergedFiles []*yaml.Node) {
+	for _, child := range node.Content {
+		replaceMergeMarkers(child, mergedFiles)
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	hasMarkers := false
+	for i := 0; i < len(node.Content); i += 2 {
+		if _, isMarker := mergeMarkerIndex(node.Content[i], len(mergedFiles)); isMarker {
+			hasMarkers = true
+			break
+		}
+	}
+
+	if !hasMarkers {
+		return
+	}
+
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		if index, isMarker := mergeMarkerIndex(key, len(mergedFiles)); isMarker {
+			mergeYAMLMappings(merged, mergedFiles[index])
+			continue
+		}
+
+		mergeYAMLMappings(merged, &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}})
+	}
+
+	node.Content = merged.Content
+}
+
+func mergeMarkerIndex(key *yaml.Node, count int) (int, bool) {
+	if key.Kind != yaml.ScalarNode || !strings.HasPrefix(key.Value, includeMergeMarkerPrefix) {
+		return 0, false
+	}
+
+	index, err := strconv.Atoi(strings.TrimPrefix(key.Value, includeMergeMarkerPrefix))
+	if err != nil || index < 0 || index >= count {
+		return 0, false
+	}
+
+	return index, true
+}
+
+// Mappings get merged recursively, any other value replaces the one in dst
+func mergeYAMLMappings(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		existing := -1
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value == key.Value {
+				existing = j + 1
+				break
+			}
+		}
+
+		if existing == -1 {
+			dst.Content = append(dst.Content, key, value)
+		} else if dst.Content[existing].Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			mergeYAMLMappings(dst.Content[existing], value)
+		} else {
+			dst.Content[existing] = value
+		}
+	}
+}
+
+func configFilesWatcher(
+	mainFilePath string,
+	lastContents []byte,
+	lastIncludes map[string]struct{},
+	onChange func(newContents []byte, trigger string),
+	onErr func(error),
+	reloadRequests <-chan string,
+) (func() error, error) {
+	mainFileAbsPath, err := filepath.Abs(mainFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("getting absolute path of main file: %w", err)
+	}
+
+	// TODO: refactor, flaky
+	lastIncludes[mainFileAbsPath] = struct{}{}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating watcher: %w", err)
+	}
+
+	updateWatchedFiles := func(previousWatched map[string]struct{}, newWatched map[string]struct{}) {
+		for filePath := range previousWatched {
+			if _, ok := newWatched[filePath]; !ok {
+				watcher.Remove(filePath)
+			}
+		}
+
+		for filePath := range newWatched {
+			if _, ok := previousWatched[filePath]; !ok {
+				if err := watcher.Add(filePath); err != nil {
+					log.Printf(
+						"Could not add file to watcher, changes to this file will not trigger a reload. path: %s, error: %v",
+						filePath, err,
+					)
+				}
+			}
+		}
+	}
+
+	updateWatchedFiles(nil, lastIncludes)
+
+	// needed for lastContents and lastIncludes because they get updated in multiple goroutines
+	mu := sync.Mutex{}
+
+	// reload requests don't skip unchanged contents since config variables only get replaced
+	// after the contents are compared, so they stay the same when only variables change
+	parseAndCallback := func(skipIfUnchanged bool, trigger string) {
+		startedAt := time.Now()
+
+		currentContents, currentIncludes, err := parseYAMLIncludes(mainFilePath)
+		if err != nil {
+			onErr(fmt.Errorf("parsing main file contents for comparison: %w", err))
+			return
+		}
+
+		includesCount := len(currentIncludes)
+
+		// TODO: refactor, flaky
+		currentIncludes[mainFileAbsPath] = struct{}{}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !maps.Equal(currentIncludes, lastIncludes) {
+			updateWatchedFiles(lastIncludes, currentIncludes)
+			lastIncludes = currentIncludes
+		}
+
+		changed := !skipIfUnchanged || !bytes.Equal(lastContents, currentContents)
+
+		// logged before the reload, which replaces the variables and logs how it went on its own,
+		// so this covers reading the files and resolving their includes and the comparison
+		slog.Debug(
+			fmt.Sprintf("Config reload check took %dms, %d includes processed", time.Since(startedAt).Milliseconds(), includesCount),
+			"trigger", trigger, "changed", changed,
+		)
+
+		if changed {
+			lastContents = currentContents
+			onChange(currentContents, trigger)
+		}
+	}
+
+	parseAndCompareBeforeCallback := func() {
+		parseAndCallback(true, "Config file changed")
+	}
+
+	const debounceDuration = 500 * time.Millisecond
+	var debounceTimer *time.Timer
+	debouncedParseAndCompareBeforeCallback := func() {
+		if debounceTimer != nil {
+			debounceTimer.Stop()
+			debounceTimer.Reset(debounceDuration)
+		} else {
+			debounceTimer = time.AfterFunc(debounceDuration, parseAndCompareBeforeCallback)
+		}
+	}
+
+	deleteLastInclude := func(filePath string) {
+		mu.Lock()
+		defer mu.Unlock()
+		fileAbsPath, _ := filepath.Abs(filePath)
+		delete(lastIncludes, fileAbsPath)
+	}
+
+	go func() {
+		for {
+			select {
+			case event, isOpen := <-watcher.Events:
+				if !isOpen {
+					return
+				}
+				// files getting created can only be seen within watched directories from !include-dir
+				if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
+					debouncedParseAndCompareBeforeCallback()
+				} else if event.Has(fsnotify.Rename) {
+					// on linux the file will no longer be watched after a rename, on windows
+					// it will continue to be watched with the new name but we have no access to
+					// the new name in this event in order to stop watching it manually and match the
+					// behavior in linux, may lead to weird unintended behaviors on windows as we're
+					// only handling renames from linux's perspective
+					// see https://github.com/fsnotify/fsnotify/issues/255
+
+					// remove the old file from our manually tracked includes, calling
+					// debouncedParseAndCompareBeforeCallback will re-add it if it's still
+					// required after it triggers
+					deleteLastInclude(event.Name)
+
+					// wait for file to maybe get created again
+					// see https://github.com/glanceapp/glance/pull/358
+					for i := 0; i < 10; i++ {
+						if _, err := os.Stat(event.Name); err == nil {
+							break
+						}
+						time.Sleep(200 * time.Millisecond)
+					}
+
+					debouncedParseAndCompareBeforeCallback()
+				} else if event.Has(fsnotify.Remove) {
+					deleteLastInclude(event.Name)
+					debouncedParseAndCompareBeforeCallback()
+				}
+			case err, isOpen := <-watcher.Errors:
+				if !isOpen {
+					return
+				}
+				onErr(fmt.Errorf("watcher error: %w", err))
+			case trigger := <-reloadRequests:
+				parseAndCallback(false, trigger)
+			}
+		}
+	}()
+
+	onChange(lastContents, "")
+
+	return func() error {
+		if debounceTimer != nil {
+			debounceTimer.Stop()
+		}
+
+		return watcher.Close()
+	}, nil
+}
+
+const defaultMaxWidgetsPerPage = 100
+
+// Counts the widgets along with any widgets nested inside of them
+func countWidgets(list widgets) int {
+	count := len(list)
+
+	for i := range list {
+		if container, ok := list[i].(widgetContainer); ok {
+			count += countWidgets(container.containedWidgets())
+		}
+	}
+
+	return count
+}
+
+var widgetStableIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Generates IDs based on the type and position for widgets which don't have one. Shared
+// widgets already have one from their definition, to which the ID of the container gets
+// prefixed when they're within one, same as for generated IDs. The places a definition is
+// used in after the first get a suffix such as -2, which gets added when rendered.
+func assignWidgetStableIDs(config *config) {
+	// the widgets within shared widgets are the same wherever the shared widget is used
+	sharedWithIDs := make(map[*sharedWidget]struct{})
+
+	var assign func(list widgets, prefix, position string, placements map[string]int)
+	assign = func(list widgets, prefix, position string, placements map[string]int) {
+		for i, widget := range list {
+			if placement, ok := widget.(*sharedWidgetPlacement); ok {
+				shared := placement.sharedWidget
+				placement.idPrefix = prefix
+
+				id := prefix + shared.getStableID()
+				placements[id]++
+				if count := placements[id]; count > 1 {
+					placement.idSuffix = "-" + strconv.Itoa(count)
+				}
+
+				if _, assigned := sharedWithIDs[shared]; !assigned {
+					sharedWithIDs[shared] = struct{}{}
+
+					if container, ok := shared.widget.(widgetContainer); ok {
+						assign(container.containedWidgets(), shared.getStableID()+"-", "", make(map[string]int))
+					}
+				}
+
+				continue
+			}
+
+			if widget.getStableID() == "" {
+				widget.setStableID(fmt.Sprintf("%s%s-%s%d", prefix, widget.GetType(), position, i+1))
+			}
+
+			if container, ok := widget.(widgetContainer); ok {
+				assign(container.containedWidgets(), widget.getStableID()+"-", "", placements)
+			}
+		}
+	}
+
+	for p := range config.Pages {
+		placements := make(map[string]int)
+
+		for c := range config.Pages[p].Columns {
+			assign(config.Pages[p].Columns[c].Widgets, "", fmt.Sprintf("%d-", c+1), placements)
+		}
+	}
+}
+
+// Calls fn with the ID of each of the widgets and the widgets within them as they end up on the
+// page, including the prefix and suffix which the widgets within a shared widget get when rendered
+func forEachWidgetStableID(list widgets, prefix, suffix string, fn func(id string) error) error {
+	for _, widget := range list {
+		if err := fn(prefix + widget.getStableID() + suffix); err != nil {
+			return err
+		}
+
+		containedPrefix, containedSuffix := prefix, suffix
+		if placement, ok := widget.(*sharedWidgetPlacement); ok {
+			containedPrefix, containedSuffix = prefix+placement.idPrefix, placement.idSuffix+suffix
+			widget = placement.widget
+		}
+
+		if container, ok := widget.(widgetContainer); ok {
+			if err := forEachWidgetStableID(container.containedWidgets(), containedPrefix, containedSuffix, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func areWidgetStableIDsValid(list widgets, seen map[string]struct{}) error {
+	return forEachWidgetStableID(list, "", "", func(id string) error {
+		if !widgetStableIDPattern.MatchString(id) {
+			return fmt.Errorf("widget id %s can only contain letters, numbers, dashes and underscores", id)
+		}
+
+		if _, exists := seen[id]; exists {
+			return fmt.Errorf("widget id %s is used by more than one widget", id)
+		}
+		seen[id] = struct{}{}
+
+		return nil
+	})
+}
+
+// Only allows the identifiers which can be used in selectors without escaping, such as .my-widget or #my_widget
+var cssIdentifierPattern = regexp.MustCompile(`^(?:--|-?[a-zA-Z_])[a-zA-Z0-9_-]*$`)
+
+// The IDs of elements on every page, which would break the page if a widget had the same one
+var reservedWidgetCSSIDs = []string{"top", "page", "page-content", "desktop-navigation"}
+
+func areWidgetCSSHooksValid(list widgets, seen map[string]widget) error {
+	for _, widget := range list {
+		for _, class := range strings.Fields(widget.getCSSClass()) {
+			if !cssIdentifierPattern.MatchString(class) {
+				return fmt.Errorf("widget css-class %s is not a valid CSS class name, it can only contain letters, numbers, dashes and underscores and cannot start with a number", class)
+			}
+		}
+
+		if id := widget.getCSSID(); id != "" {
+			if !cssIdentifierPattern.MatchString(id) {
+				return fmt.Errorf("widget css-id %s is not a valid CSS ID, it can only contain letters, numbers, dashes and underscores and cannot start with a number", id)
+			}
+
+			if strings.HasPrefix(id, "widget-") || slices.Contains(reservedWidgetCSSIDs, id) {
+				return fmt.Errorf("widget css-id %s is used by Glance itself, IDs starting with widget- and %s cannot be used", id, strings.Join(reservedWidgetCSSIDs, ", "))
+			}
+
+			// a widget that comes from a definition can show up on several pages while being a single widget
+			if other, exists := seen[id]; exists && other != widget {
+				return fmt.Errorf("widget css-id %s is used by more than one widget", id)
+			}
+			seen[id] = widget
+		}
+
+		if container, ok := widget.(widgetContainer); ok {
+			if err := areWidgetCSSHooksValid(container.containedWidgets(), seen); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Checks the number and sizes of the columns which are active on the day type, or all of them if it's empty
+func isPageColumnLayoutValid(page *page, index int, dayType string) error {
+	var count, full int
+	for c := range page.Columns {
+		if dayType != "" && !page.Columns[c].isActiveOn(dayType) {
+			continue
+		}
+
+		count++
+		if page.Columns[c].Size == "full" {
+			full++
+		}
+	}
+
+	var on string
+	if dayType != "" {
+		on = " on " + dayType
+	}
+
+	if count == 0 {
+		return fmt.Errorf("page %d has no columns%s", index+1, on)
+	}
+
+	if page.Width == "slim" {
+		if count > 2 {
+			return fmt.Errorf("page %d is slim and cannot have more than 2 columns%s", index+1, on)
+		}
+	} else if count > 3 {
+		return fmt.Errorf("page %d has more than 3 columns%s", index+1, on)
+	}
+
+	if full > 2 || full == 0 {
+		return fmt.Errorf("page %d must have either 1 or 2 full width columns%s", index+1, on)
+	}
+
+	return nil
+}
+
+// Widgets can only depend on widgets at the top level of a column on the same page
+// since those are the ones which the page updates, containers update their own widgets
+func isWidgetDependencyGraphValid(page *page) error {
+	topLevel := make(map[string]widget)
+
+	for c := range page.Columns {
+		for _, widget := range page.Columns[c].Widgets {
+			topLevel[widget.getStableID()] = widget
+
+			if container, ok := widget.(widgetContainer); ok {
+				if id, found := findWidgetWithDependencies(container.containedWidgets()); found {
+					return fmt.Errorf("widget %s: depends-on can only be used on widgets at the top level of a column", id)
+				}
+			}
+		}
+	}
+
+	for id, widget := range topLevel {
+		for _, dependency := range widget.getDependencies() {
+			if _, exists := topLevel[dependency]; !exists {
+				return fmt.Errorf("widget %s depends on %s, which is not a widget at the top level of a column on the same page", id, dependency)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	states := make(map[string]int, len(topLevel))
+	var path []string
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch states[id] {
+		case visited:
+			return nil
+		case visiting:
+			cycleStart := slices.Index(path, id)
+			return fmt.Errorf("widgets depend on each other in a cycle: %s", strings.Join(append(path[cycleStart:], id), " -> "))
+		}
+
+		states[id] = visiting
+		path = append(path, id)
+
+		for _, dependency := range topLevel[id].getDependencies() {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		states[id] = visited
+		return nil
+	}
+
+	// sorted so that the same cycle gets reported every time
+	ids := slices.Sorted(maps.Keys(topLevel))
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func findWidgetWithDependencies(list widgets) (string, bool) {
+	for _, widget := range list {
+		if len(widget.getDependencies()) > 0 {
+			return widget.getStableID(), true
+		}
+
+		if container, ok := widget.(widgetContainer); ok {
+			if id, found := findWidgetWithDependencies(container.containedWidgets()); found {
+				return id, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Title URLs which start with / are relative to the base URL. This has to happen before
+// widgets get initialized since some of them render their template during initialization.
+func prefixRelativeTitleURLs(list widgets, baseURL string, prefixed map[widget]struct{}) {
+	for _, widget := range list {
+		// widgets from definitions can appear multiple times
+		if _, done := prefixed[widget]; done {
+			continue
+		}
+		prefixed[widget] = struct{}{}
+
+		if titleURL := widget.GetTitleURL(); strings.HasPrefix(titleURL, "/") {
+			widget.setTitleURL(baseURL + titleURL)
+		}
+
+		if container, ok := widget.(widgetContainer); ok {
+			prefixRelativeTitleURLs(container.containedWidgets(), baseURL, prefixed)
+		}
+	}
+}
+
+// Widgets within split columns get the tag of the column the split column is in. Shared
+// widgets use the tag of the first column they're in since they only get rendered once.
+func applyWidgetTitleTag(list widgets, tag string, tagged map[widget]struct{}) {
+	for _, widget := range list {
+		if _, ok := tagged[widget]; ok {
+			continue
+		}
+		tagged[widget] = struct{}{}
+
+		widget.setTitleTag(tag)
+
+		if container, ok := widget.(widgetContainer); ok {
+			applyWidgetTitleTag(container.containedWidgets(), tag, tagged)
+		}
+	}
+}
+
+const minPageReloadInterval = 30 * time.Second
+
+var cssLengthPattern = regexp.MustCompile(`^\d+(?:\.\d+)?(?:px|em|rem)$`)
+
+// Between 1 and 4 lengths separated by spaces, like the CSS padding shorthand
+var cssPaddingPattern = regexp.MustCompile(`^(?:0|\d+(?:\.\d+)?(?:px|em|rem|%))(?: +(?:0|\d+(?:\.\d+)?(?:px|em|rem|%))){0,3}$`)
+
+// Only affects messages logged through slog, which is where debug messages are logged
+var logLevels = map[string]slog.Level{
+	"debug":   slog.LevelDebug,
+	"info":    slog.LevelInfo,
+	"warning": slog.LevelWarn,
+	"error":   slog.LevelError,
+}
+
+func isConfigStateValid(config *config) error {
+	if len(config.Pages) == 0 {
+		return fmt.Errorf("no pages configured")
+	}
+
+	if config.Server.Port.isInvalid {
+		return fmt.Errorf("server: port must be a number between 0 and 65535, got %q", config.Server.Port.invalidValue)
+	}
 
-	replaced := configEnvVariablePattern.ReplaceAllFunc(contents, func(match []byte) []byte {
-		if err != nil {
-			return nil
+	if config.Server.Socket != "" {
+		if config.Server.Host != "" || config.Server.Port.isSet {
+			return fmt.Errorf("server: socket cannot be used together with host or port")
 		}
+	} else if config.Server.SocketMode != "" {
+		return fmt.Errorf("server: socket-mode can only be used together with socket")
+	}
 
-		groups := configEnvVariablePattern.FindSubmatch(match)
-		if len(groups) != 3 {
-			return match
+	if config.Server.SocketMode != "" {
+		if _, err := strconv.ParseUint(config.Server.SocketMode, 8, 32); err != nil {
+			return fmt.Errorf("server: socket-mode must be an octal number such as 660, got %s", config.Server.SocketMode)
 		}
+	}
 
-		prefix, key := string(groups[1]), string(groups[2])
-		if prefix == `\` {
-			if len(match) >= 2 {
-				return match[1:]
-			} else {
-				return nil
-			}
+	if config.Server.BaseURL != "" {
+		if err := isBaseURLValid(config.Server.BaseURL); err != nil {
+			return fmt.Errorf("base-url: %v", err)
 		}
+	}
 
-		value, found := os.LookupEnv(key)
-		if !found {
-			err = fmt.Errorf("environment variable %s not found", key)
-			return nil
+	if config.Server.TLS.CertFile != "" || config.Server.TLS.KeyFile != "" {
+		if config.Server.TLS.CertFile == "" || config.Server.TLS.KeyFile == "" {
+			return fmt.Errorf("server: tls requires both cert-file and key-file")
 		}
 
-		return []byte(prefix + value)
-	})
-
-	if err != nil {
-		return nil, err
+		for _, path := range []string{config.Server.TLS.CertFile, config.Server.TLS.KeyFile} {
+			file, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("server: tls: %v", err)
+			}
+			file.Close()
+		}
 	}
 
-	return replaced, nil
-}
-
-func formatWidgetInitError(err error, w widget) error {
-	return fmt.Errorf("%s widget: %v", w.GetType(), err)
-}
+	if config.Server.HTTPClientCertFile != "" || config.Server.HTTPClientKeyFile != "" {
+		if config.Server.HTTPClientCertFile == "" || config.Server.HTTPClientKeyFile == "" {
+			return fmt.Errorf("server: http-client-cert-file and http-client-key-file must be set together")
+		}
 
-var includePattern = regexp.MustCompile(`(?m)^(\s*)!include:\s*(.+)$`)
+		for _, path := range []string{config.Server.HTTPClientCertFile, config.Server.HTTPClientKeyFile} {
+			file, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("server: http client certificate: %v", err)
+			}
+			file.Close()
+		}
+	}
 
-func parseYAMLIncludes(mainFilePath string) ([]byte, map[string]struct{}, error) {
-	mainFileContents, err := os.ReadFile(mainFilePath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("reading main YAML file: %w", err)
+	for _, name := range config.Server.WatchEnvVars {
+		if !envVarNamePattern.MatchString(name) {
+			return fmt.Errorf("server watch-env-vars: invalid variable name %q, can only contain uppercase letters, numbers and underscores", name)
+		}
 	}
 
-	mainFileAbsPath, err := filepath.Abs(mainFilePath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("getting absolute path of main YAML file: %w", err)
+	if config.Server.EnvPollInterval < 0 {
+		return fmt.Errorf("server env-poll-interval must be greater than 0")
 	}
-	mainFileDir := filepath.Dir(mainFileAbsPath)
 
-	includes := make(map[string]struct{})
-	var includesLastErr error
+	if config.Server.Proxy != "" {
+		if err := isWidgetProxyURLValid(config.Server.Proxy); err != nil {
+			return fmt.Errorf("server proxy: %v", err)
+		}
+	} else if len(config.Server.NoProxy) > 0 {
+		return fmt.Errorf("server no-proxy has no effect without setting proxy")
+	}
 
-	mainFileContents = includePattern.ReplaceAllFunc(mainFileContents, func(match []byte) []byte {
-		if includesLastErr != nil {
-			return nil
+	if config.Server.HotReloadWebhook != "" {
+		parsed, err := url.Parse(config.Server.HotReloadWebhook)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("server: hot-reload-webhook must be a full http(s) URL")
 		}
+	}
 
-		matches := includePattern.FindSubmatch(match)
-		if len(matches) != 3 {
-			includesLastErr = fmt.Errorf("invalid include match: %v", matches)
-			return nil
+	for name, value := range config.Server.Headers {
+		if !httpHeaderNamePattern.MatchString(name) {
+			return fmt.Errorf("server: headers contains an invalid header name %s", name)
 		}
 
-		indent := string(matches[1])
-		includeFilePath := strings.TrimSpace(string(matches[2]))
-		if !filepath.IsAbs(includeFilePath) {
-			includeFilePath = filepath.Join(mainFileDir, includeFilePath)
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("server: value of header %s cannot contain line breaks", name)
 		}
 
-		var fileContents []byte
-		var err error
+		if hint, managed := serverManagedResponseHeaders[http.CanonicalHeaderKey(name)]; managed {
+			if hint == "" {
+				hint = "it depends on the response and cannot be set for all of them"
+			}
 
-		fileContents, err = os.ReadFile(includeFilePath)
-		if err != nil {
-			includesLastErr = fmt.Errorf("reading included file %s: %w", includeFilePath, err)
-			return nil
+			return fmt.Errorf("server: headers cannot contain %s since it gets set by Glance itself, %s", name, hint)
 		}
+	}
 
-		includes[includeFilePath] = struct{}{}
-		return []byte(prefixStringLines(indent, string(fileContents)))
-	})
+	if userAgent := config.Server.UserAgent; userAgent != nil {
+		if strings.TrimSpace(*userAgent) == "" {
+			return fmt.Errorf("server: user-agent cannot be empty when set")
+		}
 
-	if includesLastErr != nil {
-		return nil, nil, includesLastErr
+		if strings.ContainsAny(*userAgent, "\r\n") {
+			return fmt.Errorf("server: user-agent cannot contain line breaks")
+		}
 	}
 
-	return mainFileContents, includes, nil
-}
-
-func configFilesWatcher(
-	mainFilePath string,
-	lastContents []byte,
-	lastIncludes map[string]struct{},
-	onChange func(newContents []byte),
-	onErr func(error),
-) (func() error, error) {
-	mainFileAbsPath, err := filepath.Abs(mainFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("getting absolute path of main file: %w", err)
+	if _, ok := logLevels[config.Server.LogLevel]; !ok && config.Server.LogLevel != "" {
+		return fmt.Errorf("server: log-level can only be either debug, info, warning or error")
 	}
 
-	// TODO: refactor, flaky
-	lastIncludes[mainFileAbsPath] = struct{}{}
+	if header := config.Server.RequestIDHeader; header != "" {
+		if !httpHeaderNamePattern.MatchString(header) {
+			return fmt.Errorf("server: request-id-header contains an invalid header name %s", header)
+		}
 
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, fmt.Errorf("creating watcher: %w", err)
+		for name := range config.Server.Headers {
+			if http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(header) {
+				return fmt.Errorf("server: headers cannot contain %s since it is the request-id-header", name)
+			}
+		}
+
+		if _, managed := serverManagedResponseHeaders[http.CanonicalHeaderKey(header)]; managed {
+			return fmt.Errorf("server: request-id-header cannot be %s", header)
+		}
 	}
 
-	updateWatchedFiles := func(previousWatched map[string]struct{}, newWatched map[string]struct{}) {
-		for filePath := range previousWatched {
-			if _, ok := newWatched[filePath]; !ok {
-				watcher.Remove(filePath)
-			}
+	for _, route := range config.Server.DisableRoutes {
+		if !strings.HasPrefix(route, "/") {
+			return fmt.Errorf("server: disable-routes entry %s must start with /", route)
 		}
 
-		for filePath := range newWatched {
-			if _, ok := previousWatched[filePath]; !ok {
-				if err := watcher.Add(filePath); err != nil {
-					log.Printf(
-						"Could not add file to watcher, changes to this file will not trigger a reload. path: %s, error: %v",
-						filePath, err,
-					)
-				}
+		if !slices.Contains(disableableRoutes, route) {
+			log.Printf(
+				"Warning: disable-routes entry %s does not match any built-in route that can be disabled (%s)",
+				route, strings.Join(disableableRoutes, ", "),
+			)
+		}
+	}
+
+	for _, feature := range config.Server.ExperimentalFeatures {
+		if !slices.Contains(experimentalFeatures, feature) {
+			if len(experimentalFeatures) == 0 {
+				log.Printf("Warning: experimental feature %s does not exist, this release of Glance has no experimental features", feature)
+			} else {
+				log.Printf(
+					"Warning: experimental feature %s does not exist (available: %s)",
+					feature, strings.Join(experimentalFeatures, ", "),
+				)
 			}
 		}
 	}
 
-	updateWatchedFiles(nil, lastIncludes)
+	if config.Server.DebugConfig.Enabled {
+		if config.Server.DebugConfig.Token == "" {
+			return fmt.Errorf("server: debug-config token is required since the config can contain sensitive values even with secrets redacted")
+		}
 
-	// needed for lastContents and lastIncludes because they get updated in multiple goroutines
-	mu := sync.Mutex{}
+	}
 
-	parseAndCompareBeforeCallback := func() {
-		currentContents, currentIncludes, err := parseYAMLIncludes(mainFilePath)
-		if err != nil {
-			onErr(fmt.Errorf("parsing main file contents for comparison: %w", err))
-			return
+	// without a backend widgets only keep their data in memory, which is also why there's
+	// no memory backend, it would only ever hold what the widgets themselves already have
+	switch config.Server.Cache.Backend {
+	case "":
+	case "redis":
+		if config.Server.Cache.Redis.Address == "" {
+			return fmt.Errorf("server: cache: redis address is required when using the redis backend")
 		}
 
-		// TODO: refactor, flaky
-		currentIncludes[mainFileAbsPath] = struct{}{}
+		if config.Server.Cache.Redis.DB < 0 {
+			return fmt.Errorf("server: cache: redis db cannot be negative")
+		}
+	default:
+		return fmt.Errorf("server: cache: backend can only be redis, leave it unset to not use a shared cache, got %s", config.Server.Cache.Backend)
+	}
 
-		mu.Lock()
-		defer mu.Unlock()
+	if config.Server.WidgetHTTPTransport.MaxIdleConns < 0 {
+		return fmt.Errorf("widget-http-transport: max-idle-conns cannot be negative")
+	}
 
-		if !maps.Equal(currentIncludes, lastIncludes) {
-			updateWatchedFiles(lastIncludes, currentIncludes)
-			lastIncludes = currentIncludes
+	for _, path := range config.Server.AssetsPath {
+		if path == "" {
+			return fmt.Errorf("assets-path cannot contain empty paths")
 		}
 
-		if !bytes.Equal(lastContents, currentContents) {
-			lastContents = currentContents
-			onChange(currentContents)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("assets directory does not exist: %s", path)
 		}
 	}
 
-	const debounceDuration = 500 * time.Millisecond
-	var debounceTimer *time.Timer
-	debouncedParseAndCompareBeforeCallback := func() {
-		if debounceTimer != nil {
-			debounceTimer.Stop()
-			debounceTimer.Reset(debounceDuration)
-		} else {
-			debounceTimer = time.AfterFunc(debounceDuration, parseAndCompareBeforeCallback)
+	for _, asset := range []struct{ property, path string }{
+		{"favicon-url", config.Branding.FaviconURL},
+		{"logo-url", config.Branding.LogoURL},
+	} {
+		if err := checkUserDefinedAssetExists(config.Server.AssetsPath, asset.path); err != nil {
+			return fmt.Errorf("branding: %s: %v", asset.property, err)
 		}
 	}
 
-	deleteLastInclude := func(filePath string) {
-		mu.Lock()
-		defer mu.Unlock()
-		fileAbsPath, _ := filepath.Abs(filePath)
-		delete(lastIncludes, fileAbsPath)
+	switch config.Branding.Banner.Severity {
+	case "", "info", "warning", "critical":
+	default:
+		return fmt.Errorf("branding: banner severity can only be either info, warning or critical")
 	}
 
-	go func() {
-		for {
-			select {
-			case event, isOpen := <-watcher.Events:
-				if !isOpen {
-					return
-				}
-				if event.Has(fsnotify.Write) {
-					debouncedParseAndCompareBeforeCallback()
-				} else if event.Has(fsnotify.Rename) {
-					// on linux the file will no longer be watched after a rename, on windows
-					// it will continue to be watched with the new name but we have no access to
-					// the new name in this event in order to stop watching it manually and match the
-					// behavior in linux, may lead to weird unintended behaviors on windows as we're
-					// only handling renames from linux's perspective
-					// see https://github.com/fsnotify/fsnotify/issues/255
+	switch config.Theme.WidgetHeaderAlign {
+	case "", "left", "center", "right":
+	default:
+		return fmt.Errorf("theme: widget-header-align can only be either left, center or right")
+	}
 
-					// remove the old file from our manually tracked includes, calling
-					// debouncedParseAndCompareBeforeCallback will re-add it if it's still
-					// required after it triggers
-					deleteLastInclude(event.Name)
+	switch config.Theme.TooltipStyle {
+	case "", "dark", "light", "none":
+	default:
+		return fmt.Errorf("theme: tooltip-style can only be either dark, light or none")
+	}
 
-					// wait for file to maybe get created again
-					// see https://github.com/glanceapp/glance/pull/358
-					for i := 0; i < 10; i++ {
-						if _, err := os.Stat(event.Name); err == nil {
-							break
-						}
-						time.Sleep(200 * time.Millisecond)
-					}
+	switch config.Theme.NavTextTransform {
+	case "", "uppercase", "capitalize", "lowercase", "none":
+	default:
+		return fmt.Errorf("theme: nav-text-transform can only be either uppercase, capitalize, lowercase or none")
+	}
 
-					debouncedParseAndCompareBeforeCallback()
-				} else if event.Has(fsnotify.Remove) {
-					deleteLastInclude(event.Name)
-					debouncedParseAndCompareBeforeCallback()
-				}
-			case err, isOpen := <-watcher.Errors:
-				if !isOpen {
-					return
-				}
-				onErr(fmt.Errorf("watcher error: %w", err))
-			}
-		}
-	}()
+	if config.Server.MaxWidgetsPerPage < 0 {
+		return fmt.Errorf("server: max-widgets-per-page cannot be negative")
+	}
 
-	onChange(lastContents)
+	maxWidgetsPerPage := config.Server.MaxWidgetsPerPage
+	if maxWidgetsPerPage == 0 {
+		maxWidgetsPerPage = defaultMaxWidgetsPerPage
+	}
 
-	return func() error {
-		if debounceTimer != nil {
-			debounceTimer.Stop()
+	keyboardShortcuts := make(map[string]int)
+	sections := make(map[string]int)
+	// unlike widget IDs, these have to be unique across all pages since they're meant for the custom CSS of the whole config
+	widgetCSSIDs := make(map[string]widget)
+
+	for i := range config.Pages {
+		if config.Pages[i].Title == "" {
+			return fmt.Errorf("page %d has no name", i+1)
 		}
 
-		return watcher.Close()
-	}, nil
-}
+		if config.Pages[i].KeyboardShortcut != "" {
+			shortcut, err := normalizeKeyboardShortcut(config.Pages[i].KeyboardShortcut)
+			if err != nil {
+				return fmt.Errorf("page %d: %v", i+1, err)
+			}
 
-func isConfigStateValid(config *config) error {
-	if len(config.Pages) == 0 {
-		return fmt.Errorf("no pages configured")
-	}
+			if other, exists := keyboardShortcuts[shortcut]; exists {
+				return fmt.Errorf("page %d: keyboard shortcut %s is already used by page %d", i+1, shortcut, other)
+			}
 
-	if config.Server.AssetsPath != "" {
-		if _, err := os.Stat(config.Server.AssetsPath); os.IsNotExist(err) {
-			return fmt.Errorf("assets directory does not exist: %s", config.Server.AssetsPath)
+			keyboardShortcuts[shortcut] = i + 1
 		}
-	}
 
-	for i := range config.Pages {
-		if config.Pages[i].Title == "" {
-			return fmt.Errorf("page %d has no name", i+1)
+		if section := config.Pages[i].Section; section != "" {
+			if strings.TrimSpace(section) != section {
+				return fmt.Errorf("page %d: section name cannot have leading or trailing whitespace", i+1)
+			}
+
+			// catch typos such as "Media" and "media" which would otherwise end up as two separate sections
+			if other, exists := sections[strings.ToLower(section)]; exists && config.Pages[other-1].Section != section {
+				return fmt.Errorf("page %d: section %s differs only in case from section %s used by page %d", i+1, section, config.Pages[other-1].Section, other)
+			} else if !exists {
+				sections[strings.ToLower(section)] = i + 1
+			}
 		}
 
 		if config.Pages[i].Width != "" && (config.Pages[i].Width != "wide" && config.Pages[i].Width != "slim") {
 			return fmt.Errorf("page %d: width can only be either wide or slim", i+1)
 		}
 
+		if interval := config.Pages[i].ReloadInterval; interval != 0 && time.Duration(interval) < minPageReloadInterval {
+			return fmt.Errorf("page %d: reload-interval must be at least %s", i+1, minPageReloadInterval)
+		}
+
+		if err := checkUserDefinedAssetExists(config.Server.AssetsPath, config.Pages[i].CustomCSSFile); err != nil {
+			return fmt.Errorf("page %d: custom-css-file: %v", i+1, err)
+		}
+
 		if len(config.Pages[i].Columns) == 0 {
 			return fmt.Errorf("page %d has no columns", i+1)
 		}
 
-		if config.Pages[i].Width == "slim" {
-			if len(config.Pages[i].Columns) > 2 {
-				return fmt.Errorf("page %d is slim and cannot have more than 2 columns", i+1)
+		hasActiveOn := false
+
+		for j := range config.Pages[i].Columns {
+			if config.Pages[i].Columns[j].Size != "small" && config.Pages[i].Columns[j].Size != "full" {
+				return fmt.Errorf("column %d of page %d: size can only be either small or full", j+1, i+1)
+			}
+
+			switch config.Pages[i].Columns[j].ActiveOn {
+			case "":
+			case columnActiveOnWeekdays, columnActiveOnWeekends:
+				hasActiveOn = true
+			default:
+				return fmt.Errorf("column %d of page %d: active-on can only be either weekdays or weekends", j+1, i+1)
 			}
-		} else {
-			if len(config.Pages[i].Columns) > 3 {
-				return fmt.Errorf("page %d has more than 3 columns", i+1)
+
+			switch config.Pages[i].Columns[j].WidgetListStyle {
+			case "", "card", "row", "compact":
+			default:
+				return fmt.Errorf("column %d of page %d: widget-list-style can only be either card, row or compact", j+1, i+1)
+			}
+
+			if padding := config.Pages[i].Columns[j].WidgetPadding; padding != "" && !cssPaddingPattern.MatchString(padding) {
+				return fmt.Errorf("column %d of page %d: widget-padding-override must be between 1 and 4 lengths in px, em, rem or %%, got %s", j+1, i+1, padding)
+			}
+
+			if height := config.Pages[i].Columns[j].WidgetImageMaxHeight; height != "" && !cssLengthPattern.MatchString(height) {
+				return fmt.Errorf("column %d of page %d: widget-image-max-height must be a number followed by px, em or rem, got %s", j+1, i+1, height)
+			}
+
+			switch config.Pages[i].Columns[j].WidgetTitleTag {
+			case "", "hidden", "text", "icon", "both":
+			default:
+				return fmt.Errorf("column %d of page %d: widget-title-tag can only be either hidden, text, icon or both", j+1, i+1)
 			}
 		}
 
-		columnSizesCount := make(map[string]int)
+		// with active-on, the columns of weekdays and weekends make up separate layouts
+		dayTypes := []string{""}
+		if hasActiveOn {
+			dayTypes = columnDayTypes
+		}
+
+		for _, dayType := range dayTypes {
+			if err := isPageColumnLayoutValid(&config.Pages[i], i, dayType); err != nil {
+				return err
+			}
+		}
 
+		widgetCount := 0
 		for j := range config.Pages[i].Columns {
-			if config.Pages[i].Columns[j].Size != "small" && config.Pages[i].Columns[j].Size != "full" {
-				return fmt.Errorf("column %d of page %d: size can only be either small or full", j+1, i+1)
+			widgetCount += countWidgets(config.Pages[i].Columns[j].Widgets)
+		}
+
+		if widgetCount > maxWidgetsPerPage {
+			return fmt.Errorf(
+				"page %s has %d widgets which is more than the maximum of %d (see server.max-widgets-per-page)",
+				config.Pages[i].Title, widgetCount, maxWidgetsPerPage,
+			)
+		}
+
+		widgetIDs := make(map[string]struct{})
+		for j := range config.Pages[i].Columns {
+			if err := areWidgetStableIDsValid(config.Pages[i].Columns[j].Widgets, widgetIDs); err != nil {
+				return fmt.Errorf("page %d: %v", i+1, err)
+			}
+
+			if err := areWidgetCSSHooksValid(config.Pages[i].Columns[j].Widgets, widgetCSSIDs); err != nil {
+				return fmt.Errorf("page %d: %v", i+1, err)
 			}
+		}
 
-			columnSizesCount[config.Pages[i].Columns[j].Size]++
+		if err := isWidgetDependencyGraphValid(&config.Pages[i]); err != nil {
+			return fmt.Errorf("page %d: %v", i+1, err)
 		}
+	}
+
+	return nil
+}
+
+// Headers which get set by the server or by the handlers depending on the response, setting
+// them for every response would break things such as the content type of assets
+var serverManagedResponseHeaders = map[string]string{
+	"Content-Type":      "",
+	"Content-Length":    "",
+	"Content-Encoding":  "",
+	"Transfer-Encoding": "",
+	"Location":          "",
+	"Set-Cookie":        "",
+	"Www-Authenticate":  "use the token of server.metrics to protect the metrics endpoint instead",
+	"Etag":              "use enable-etag for ETags which match the contents of pages instead",
+}
 
-		full := columnSizesCount["full"]
+// token characters as defined in RFC 9110
+var httpHeaderNamePattern = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
 
-		if full > 2 || full == 0 {
-			return fmt.Errorf("page %d must have either 1 or 2 full width columns", i+1)
+func isBaseURLValid(baseURL string) error {
+	if strings.HasPrefix(baseURL, "/") {
+		if strings.HasPrefix(baseURL, "//") {
+			return fmt.Errorf("must not start with //: %s", baseURL)
 		}
+
+		return nil
+	}
+
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("parsing URL: %v", err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("must either start with / or be a full http(s) URL: %s", baseURL)
+	}
+
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("must not contain a query or fragment: %s", baseURL)
 	}
 
 	return nil
 }
+
+var keyboardShortcutModifiers = []string{"ctrl", "alt", "shift", "meta"}
+var keyboardShortcutKeyPattern = regexp.MustCompile(`^[a-z0-9]$`)
+
+// Validates a shortcut in the format of modifier+key (such as alt+1 or ctrl+shift+h)
+// and returns it in lowercase with the modifiers in a consistent order
+func normalizeKeyboardShortcut(shortcut string) (string, error) {
+	parts := strings.Split(strings.ToLower(strings.ReplaceAll(shortcut, " ", "")), "+")
+
+	if len(parts) < 2 {
+		return "", fmt.Errorf("keyboard shortcut %s must be in the format of modifier+key", shortcut)
+	}
+
+	key := parts[len(parts)-1]
+	if !keyboardShortcutKeyPattern.MatchString(key) {
+		return "", fmt.Errorf("keyboard shortcut %s must end with a single letter or digit", shortcut)
+	}
+
+	used := make(map[string]bool)
+	for _, modifier := range parts[:len(parts)-1] {
+		if !slices.Contains(keyboardShortcutModifiers, modifier) {
+			return "", fmt.Errorf(
+				"keyboard shortcut %s has unknown modifier %s, must be one of %s",
+				shortcut, modifier, strings.Join(keyboardShortcutModifiers, ", "),
+			)
+		}
+
+		if used[modifier] {
+			return "", fmt.Errorf("keyboard shortcut %s has modifier %s specified more than once", shortcut, modifier)
+		}
+
+		used[modifier] = true
+	}
+
+	normalized := make([]string, 0, len(parts))
+	for _, modifier := range keyboardShortcutModifiers {
+		if used[modifier] {
+			normalized = append(normalized, modifier)
+		}
+	}
+
+	return strings.Join(append(normalized, key), "+"), nil
+}
@@ -3,14 +3,24 @@ package glance
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
+	"log/slog"
+	"maps"
+	"net"
 	"net/http"
+	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -25,25 +35,74 @@ type application struct {
 	Config           config
 	ParsedThemeStyle template.HTML
 
-	slugToPage map[string]*page
-	widgetByID map[uint64]widget
+	Navigation []navigationItem
+
+	tlsCertificate *tlsCertificateReloader
+	widgetByID     map[uint64]widget
+	// nil when responses only get cached by the widgets themselves
+	widgetCacheBackend widgetCacheBackend
+
+	// nil when asset-fingerprinting is disabled, replaced whenever the assets change
+	assetFingerprints atomic.Pointer[assetFingerprints]
+	// appended to the URLs of the custom CSS and script files so that browsers load them again
+	// after the server starts or any of the assets change, even when they aren't fingerprinted
+	assetsVersion atomic.Int64
+
+	// changes whenever the banner does so that dismissing one doesn't also dismiss future ones
+	bannerID string
+
+	// cancelled once the server has stopped, which stops the widget updates still running in the background
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func newApplication(config *config) (*application, error) {
 	app := &application{
 		Version:    buildVersion,
 		Config:     *config,
-		slugToPage: make(map[string]*page),
 		widgetByID: make(map[uint64]widget),
 	}
 
-	app.slugToPage[""] = &config.Pages[0]
+	app.ctx, app.cancel = context.WithCancel(context.Background())
+
+	app.Config.Server.BaseURL = strings.TrimRight(app.Config.Server.BaseURL, "/")
+
+	if config.Server.AssetFingerprinting && len(config.Server.AssetsPath) > 0 {
+		fingerprints, err := computeAssetFingerprints(config.Server.AssetsPath)
+		if err != nil {
+			return nil, fmt.Errorf("computing asset fingerprints: %v", err)
+		}
+
+		app.assetFingerprints.Store(newAssetFingerprints(fingerprints, nil))
+	}
+
+	app.widgetCacheBackend = newWidgetCacheBackend(&config.Server.Cache)
 
 	providers := &widgetProviders{
-		assetResolver: app.AssetPath,
+		assetResolver:     app.AssetPath,
+		userAssetResolver: app.UserAssetPath,
+		fetchJitter:       time.Duration(config.Server.FetchJitter),
+
+		minRefreshInterval: time.Duration(config.Server.MinRefreshInterval),
+
+		httpClients: newWidgetHTTPClients(
+			&config.Server.WidgetHTTPTransport,
+			newWidgetProxyFunc(config.Server.Proxy, config.Server.NoProxy),
+			app.widgetCacheBackend,
+			config.Server.Cache.KeyPrefix,
+			config.Server.UserAgent,
+			config.Server.httpClientCertificate,
+		),
 	}
 
 	var err error
+	if config.Server.TLS.CertFile != "" {
+		app.tlsCertificate, err = newTLSCertificateReloader(config.Server.TLS.CertFile, config.Server.TLS.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	app.ParsedThemeStyle, err = executeTemplateToHTML(pageThemeStyleTemplate, &app.Config.Theme)
 	if err != nil {
 		return nil, fmt.Errorf("parsing theme style: %v", err)
@@ -51,21 +110,28 @@ func newApplication(config *config) (*application, error) {
 
 	for p := range config.Pages {
 		page := &config.Pages[p]
-		page.PrimaryColumnIndex = -1
 
-		if page.Slug == "" {
-			page.Slug = titleToSlug(page.Title)
+		if page.Theme == nil {
+			page.theme = &app.Config.Theme
+			page.themeStyle = app.ParsedThemeStyle
+		} else {
+			theme := app.Config.Theme.withOverrides(page.Theme)
+			page.theme = &theme
+
+			page.themeStyle, err = executeTemplateToHTML(pageThemeStyleTemplate, page.theme)
+			if err != nil {
+				return nil, fmt.Errorf("parsing theme style of page %s: %v", page.Title, err)
+			}
 		}
 
-		app.slugToPage[page.Slug] = page
+		if page.KeyboardShortcut != "" {
+			// already validated, only normalizing here
+			page.KeyboardShortcut, _ = normalizeKeyboardShortcut(page.KeyboardShortcut)
+		}
 
 		for c := range page.Columns {
 			column := &page.Columns[c]
 
-			if page.PrimaryColumnIndex == -1 && column.Size == "full" {
-				page.PrimaryColumnIndex = int8(c)
-			}
-
 			for w := range column.Widgets {
 				widget := column.Widgets[w]
 				app.widgetByID[widget.GetID()] = widget
@@ -75,114 +141,381 @@ func newApplication(config *config) (*application, error) {
 		}
 	}
 
-	config = &app.Config
+	app.Navigation = newNavigation(config.Pages)
 
-	config.Server.BaseURL = strings.TrimRight(config.Server.BaseURL, "/")
-	config.Theme.CustomCSSFile = app.transformUserDefinedAssetPath(config.Theme.CustomCSSFile)
+	config = &app.Config
 
+	// the rest of the user defined assets used by the page get resolved when rendering it
+	// through UserAssetPath, which keeps their URLs up to date as the assets change
 	if config.Branding.FaviconURL == "" {
 		config.Branding.FaviconURL = app.AssetPath("favicon.png")
-	} else {
-		config.Branding.FaviconURL = app.transformUserDefinedAssetPath(config.Branding.FaviconURL)
 	}
 
-	config.Branding.LogoURL = app.transformUserDefinedAssetPath(config.Branding.LogoURL)
+	if config.Branding.Banner.Content != "" {
+		if config.Branding.Banner.Severity == "" {
+			config.Branding.Banner.Severity = "info"
+		}
+
+		hash := sha256.Sum256([]byte(config.Branding.Banner.Severity + "\n" + string(config.Branding.Banner.Content)))
+		app.bannerID = hex.EncodeToString(hash[:8])
+	}
 
 	return app, nil
 }
 
-func (p *page) updateOutdatedWidgets() {
+// Either a single page or a named section of pages
+type navigationItem struct {
+	Page    *page
+	Section string
+	Pages   []*page
+}
+
+// Groups pages by their section in the order that each section first appears,
+// pages without a section stay where they are
+func newNavigation(pages []page) []navigationItem {
+	items := make([]navigationItem, 0, len(pages))
+	sectionIndex := make(map[string]int)
+
+	for i := range pages {
+		p := &pages[i]
+
+		if p.Section == "" {
+			items = append(items, navigationItem{Page: p})
+			continue
+		}
+
+		if index, exists := sectionIndex[p.Section]; exists {
+			items[index].Pages = append(items[index].Pages, p)
+			continue
+		}
+
+		sectionIndex[p.Section] = len(items)
+		items = append(items, navigationItem{Section: p.Section, Pages: []*page{p}})
+	}
+
+	return items
+}
+
+func (item *navigationItem) ContainsPage(slug string) bool {
+	for _, page := range item.Pages {
+		if page.Slug == slug {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Keeps track of the widgets that are still updating, so that the ones
+// holding up a page can be logged when it takes too long to render
+type widgetUpdateProgress struct {
+	mu      sync.Mutex
+	pending map[widget]struct{}
+}
+
+func (p *widgetUpdateProgress) started(w widget) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pending == nil {
+		p.pending = make(map[widget]struct{})
+	}
+	p.pending[w] = struct{}{}
+}
+
+func (p *widgetUpdateProgress) finished(w widget) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.pending, w)
+}
+
+// Returns the type and title of each widget that's still updating
+func (p *widgetUpdateProgress) pendingWidgets() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	names := make([]string, 0, len(p.pending))
+	for w := range p.pending {
+		names = append(names, fmt.Sprintf("%s (%s)", w.GetTitle(), w.GetType()))
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Only updates the widgets of the given columns. The progress can be nil if it doesn't need to be tracked.
+func (p *page) updateOutdatedWidgets(ctx context.Context, columns []*pageColumn, progress *widgetUpdateProgress) {
 	now := time.Now()
 
 	var wg sync.WaitGroup
-	context := context.Background()
+	context := contextWithPageSlug(ctx, p.Slug)
 
-	for c := range p.Columns {
-		for w := range p.Columns[c].Widgets {
-			widget := p.Columns[c].Widgets[w]
+	var outdated []widget
+	updated := make(map[string]chan struct{})
+
+	for _, column := range columns {
+		for _, widget := range column.Widgets {
 
 			if !widget.requiresUpdate(&now) {
 				continue
 			}
 
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				widget.update(context)
-			}()
+			outdated = append(outdated, widget)
+			updated[widget.getStableID()] = make(chan struct{})
 		}
 	}
 
+	for _, widget := range outdated {
+		// the first update of a widget waits for the widgets it depends on, as long as
+		// they're also being updated. cycles are rejected when validating the config.
+		var dependencies []chan struct{}
+		if widget.isAwaitingFirstUpdate() {
+			for _, id := range widget.getDependencies() {
+				if done, exists := updated[id]; exists {
+					dependencies = append(dependencies, done)
+				}
+			}
+		}
+
+		// IDs are unique within the page, including those of widgets from definitions
+		// which get a suffix for each place they're used in after the first one
+		done := updated[widget.getStableID()]
+
+		wg.Add(1)
+		progress.started(widget)
+		go func() {
+			defer wg.Done()
+			defer progress.finished(widget)
+			defer close(done)
+
+			for _, dependency := range dependencies {
+				<-dependency
+			}
+
+			updateWidgetWithTimeout(context, widget)
+		}()
+	}
+
 	wg.Wait()
 }
 
-func (a *application) transformUserDefinedAssetPath(path string) string {
-	if strings.HasPrefix(path, "/assets/") {
+// Turns paths such as /assets/custom.css, as they're written in the config, into their URL
+func (a *application) UserAssetPath(path string) string {
+	if asset, found := strings.CutPrefix(path, "/assets/"); found {
+		if fingerprinted, ok := a.assetFingerprints.Load().fingerprintOf(asset); ok {
+			return a.Config.Server.BaseURL + "/assets/" + fingerprinted
+		}
+
 		return a.Config.Server.BaseURL + path
 	}
 
 	return path
 }
 
+func (a *application) handleFingerprintedAssetRequest(assetsFS http.Handler) http.Handler {
+	fingerprintedFS := fileServerWithCache(newMultiDirFileSystem(a.Config.Server.AssetsPath), 365*24*time.Hour)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asset := strings.TrimPrefix(r.URL.Path, "/")
+		fingerprints := a.assetFingerprints.Load()
+
+		if original, ok := fingerprints.original[asset]; ok {
+			// URLs with an older fingerprint may have already been rendered, such as for widget icons
+			if fingerprinted := fingerprints.fingerprinted[original]; fingerprinted != asset {
+				http.Redirect(w, r, a.Config.Server.BaseURL+"/assets/"+fingerprinted, http.StatusFound)
+				return
+			}
+
+			r.URL.Path = "/" + original
+			fingerprintedFS.ServeHTTP(w, r)
+			return
+		}
+
+		if fingerprinted, ok := fingerprints.fingerprinted[asset]; ok {
+			http.Redirect(w, r, a.Config.Server.BaseURL+"/assets/"+fingerprinted, http.StatusFound)
+			return
+		}
+
+		assetsFS.ServeHTTP(w, r)
+	})
+}
+
 type pageTemplateData struct {
-	App  *application
-	Page *page
+	App        *application
+	Page       *page
+	TabTitle   string
+	Theme      *themeProperties
+	ThemeStyle template.HTML
+	ShowBanner bool
+	// The columns which are active at the time of the request, see pageColumn.ActiveOn
+	Columns            []*pageColumn
+	PrimaryColumnIndex int
+	// Set when the page is shown inside of a page-embed widget, which leaves out
+	// everything but the columns since the page that embeds it already has them
+	Embedded bool
+}
+
+type tabTitleTemplateData struct {
+	Page   *page
+	Server any
+}
+
+func (a *application) tabTitleForPage(ctx context.Context, page *page) string {
+	if page.tabTitleTemplate == nil {
+		return page.Title
+	}
+
+	var title strings.Builder
+	err := page.tabTitleTemplate.Execute(&title, tabTitleTemplateData{
+		Page:   page,
+		Server: &a.Config.Server,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to execute tab title template", "page", page.Slug, "error", err)
+		return page.Title
+	}
+
+	return title.String()
 }
 
 func (a *application) handlePageRequest(w http.ResponseWriter, r *http.Request) {
-	page, exists := a.slugToPage[r.PathValue("page")]
+	page, exists := a.Config.PageBySlug(r.PathValue("page"))
 
 	if !exists {
 		a.handleNotFound(w, r)
 		return
 	}
 
+	embedded := r.URL.Query().Has("embed")
+	columns := page.activeColumns(time.Now())
+
 	pageData := pageTemplateData{
-		Page: page,
-		App:  a,
+		Page:       page,
+		App:        a,
+		TabTitle:   a.tabTitleForPage(r.Context(), page),
+		Theme:      page.theme,
+		ThemeStyle: page.themeStyle,
+		ShowBanner: a.bannerID != "" && !embedded && !a.isBannerDismissed(r),
+		Embedded:   embedded,
+		Columns:    columns,
+
+		PrimaryColumnIndex: primaryColumnIndex(columns),
 	}
 
 	var responseBytes bytes.Buffer
 	err := pageTemplate.Execute(&responseBytes, pageData)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
-		return
-	}
+	a.writePageResponse(w, r, responseBytes.Bytes(), err)
+}
+
+func (a *application) BannerID() string {
+	return a.bannerID
+}
+
+const bannerDismissedCookieName = "glance_banner_dismissed"
 
-	w.Write(responseBytes.Bytes())
+// The cookie holds the ID of the banner that was dismissed, set by the dismiss button of the banner
+func (a *application) isBannerDismissed(r *http.Request) bool {
+	cookie, err := r.Cookie(bannerDismissedCookieName)
+	return err == nil && cookie.Value == a.bannerID
 }
 
 func (a *application) handlePageContentRequest(w http.ResponseWriter, r *http.Request) {
-	page, exists := a.slugToPage[r.PathValue("page")]
+	page, exists := a.Config.PageBySlug(r.PathValue("page"))
 
 	if !exists {
 		a.handleNotFound(w, r)
 		return
 	}
 
-	pageData := pageTemplateData{
-		Page: page,
+	renderTimeout := time.Duration(page.RenderTimeout)
+	if renderTimeout <= 0 {
+		content, err := a.renderPageContent(r.Context(), page, nil)
+		a.writePageResponse(w, r, content, err)
+		return
 	}
 
-	var err error
-	var responseBytes bytes.Buffer
+	ctx, cancel := context.WithTimeout(r.Context(), renderTimeout)
+	defer cancel()
 
-	func() {
-		page.mu.Lock()
-		defer page.mu.Unlock()
+	type renderResult struct {
+		content []byte
+		err     error
+	}
+
+	progress := &widgetUpdateProgress{}
+	rendered := make(chan renderResult, 1)
 
-		page.updateOutdatedWidgets()
-		err = pageContentTemplate.Execute(&responseBytes, pageData)
+	// keeps going after the timeout so that the widgets still get updated for the next request
+	go func() {
+		content, err := a.renderPageContent(r.Context(), page, progress)
+		rendered <- renderResult{content, err}
 	}()
 
+	select {
+	case result := <-rendered:
+		a.writePageResponse(w, r, result.content, result.err)
+	case <-ctx.Done():
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return
+		}
+
+		if pending := progress.pendingWidgets(); len(pending) > 0 {
+			slog.WarnContext(r.Context(), "Page render timed out while widgets were updating",
+				"page", page.Slug, "timeout", renderTimeout, "widgets", strings.Join(pending, ", "))
+		} else {
+			slog.WarnContext(r.Context(), "Page render timed out while waiting for another render of the page or rendering its template",
+				"page", page.Slug, "timeout", renderTimeout)
+		}
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "page render timed out after %s", renderTimeout)
+	}
+}
+
+func (a *application) renderPageContent(ctx context.Context, page *page, progress *widgetUpdateProgress) ([]byte, error) {
+	page.mu.Lock()
+	defer page.mu.Unlock()
+
+	// picked once so that the columns that get updated are the same ones that get rendered
+	columns := page.activeColumns(time.Now())
+
+	// the updates shouldn't get cancelled if the client goes away, since the results are
+	// cached and also used by the requests of other clients, only once the server stops
+	updateCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	stopCancellingOnShutdown := context.AfterFunc(a.ctx, cancel)
+	defer stopCancellingOnShutdown()
+
+	page.updateOutdatedWidgets(updateCtx, columns, progress)
+
+	var responseBytes bytes.Buffer
+	err := pageContentTemplate.Execute(&responseBytes, pageTemplateData{Page: page, Columns: columns})
+
+	return responseBytes.Bytes(), err
+}
+
+func (a *application) writePageResponse(w http.ResponseWriter, r *http.Request, content []byte, err error) {
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(err.Error()))
 		return
 	}
 
-	w.Write(responseBytes.Bytes())
+	if a.Config.Server.EnableETag {
+		writeWithETag(w, r, content)
+		return
+	}
+
+	w.Write(content)
 }
 
 func (a *application) handleNotFound(w http.ResponseWriter, _ *http.Request) {
@@ -214,55 +547,325 @@ func (a *application) AssetPath(asset string) string {
 	return a.Config.Server.BaseURL + "/static/" + staticFSHash + "/" + asset
 }
 
+func (a *application) AssetsVersion() int64 {
+	return a.assetsVersion.Load()
+}
+
+type assetFingerprints struct {
+	// original asset path -> fingerprinted asset path and vice versa,
+	// both relative to the assets directory
+	fingerprinted map[string]string
+	original      map[string]string
+}
+
+// The fingerprinted paths of the previous fingerprints are kept so that requests for
+// them can be redirected to the latest version rather than not being found
+func newAssetFingerprints(fingerprints map[string]string, previous *assetFingerprints) *assetFingerprints {
+	original := make(map[string]string, len(fingerprints))
+	if previous != nil {
+		maps.Copy(original, previous.original)
+	}
+
+	for asset, fingerprinted := range fingerprints {
+		original[fingerprinted] = asset
+	}
+
+	return &assetFingerprints{fingerprinted: fingerprints, original: original}
+}
+
+func (f *assetFingerprints) fingerprintOf(asset string) (string, bool) {
+	if f == nil {
+		return "", false
+	}
+
+	fingerprinted, ok := f.fingerprinted[asset]
+	return fingerprinted, ok
+}
+
+// Called when a file within the assets paths changes so that the URLs of assets rendered
+// from now on point to their latest version, without having to reload the whole config
+func (a *application) refreshAssets() {
+	if previous := a.assetFingerprints.Load(); previous != nil {
+		fingerprints, err := computeAssetFingerprints(a.Config.Server.AssetsPath)
+		if err != nil {
+			log.Printf("Failed to update asset fingerprints: %v", err)
+			return
+		}
+
+		a.assetFingerprints.Store(newAssetFingerprints(fingerprints, previous))
+	}
+
+	a.assetsVersion.Store(time.Now().UnixMilli())
+}
+
+func (a *application) listenOnUnixSocket() (net.Listener, error) {
+	socketPath := a.Config.Server.Socket
+
+	// remove any leftover socket from a previous run that didn't shut down cleanly
+	if stat, err := os.Stat(socketPath); err == nil {
+		if stat.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("socket path %s exists and is not a socket", socketPath)
+		}
+
+		if err := os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("removing existing socket: %w", err)
+		}
+	}
+
+	// the socket gets created with the default permissions, so it's created within a directory that only
+	// the current user can access and only moved into place once it has the permissions from socket-mode
+	privateDir, err := os.MkdirTemp(filepath.Dir(socketPath), ".glance-")
+	if err != nil {
+		return nil, fmt.Errorf("creating directory for socket: %w", err)
+	}
+	defer os.RemoveAll(privateDir)
+
+	privatePath := filepath.Join(privateDir, "s")
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: privatePath, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("listening on socket: %w", err)
+	}
+
+	// the listener would remove the path it was created with rather than the one it gets moved to
+	listener.SetUnlinkOnClose(false)
+
+	mode, _ := strconv.ParseUint(a.Config.Server.SocketMode, 8, 32)
+	if err := os.Chmod(privatePath, os.FileMode(mode)); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("setting socket permissions: %w", err)
+	}
+
+	if err := os.Rename(privatePath, socketPath); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("moving socket into place: %w", err)
+	}
+
+	return &unixSocketListener{Listener: listener, path: socketPath}, nil
+}
+
+type unixSocketListener struct {
+	net.Listener
+	path      string
+	closeOnce sync.Once
+}
+
+// Removes the socket only on the first close, since by the time of a later one the
+// path could belong to the socket of the server that replaced this one on reload
+func (l *unixSocketListener) Close() error {
+	err := l.Listener.Close()
+	l.closeOnce.Do(func() { os.Remove(l.path) })
+
+	return err
+}
+
+func (a *application) serve(server *http.Server, listener net.Listener) error {
+	if a.tlsCertificate != nil {
+		// the certificate comes from TLSConfig.GetCertificate
+		return server.ServeTLS(listener, "", "")
+	}
+
+	return server.Serve(listener)
+}
+
+// Built-in endpoints which can be turned off through server.disable-routes,
+// the rest are required for pages to load
+var disableableRoutes = []string{
+	"/api/healthz",
+	"/api/widgets",
+	"/metrics",
+	"/debug/config",
+}
+
+func (a *application) isRouteDisabled(route string) bool {
+	return slices.Contains(a.Config.Server.DisableRoutes, route)
+}
+
+const defaultShutdownTimeout = 10 * time.Second
+
+func (a *application) shutdownTimeout() time.Duration {
+	if a.Config.Server.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+
+	return time.Duration(a.Config.Server.ShutdownTimeout)
+}
+
+// Applies the settings which are shared by the whole process rather than kept within the application,
+// which has to wait until it replaces the previous one so that a reload which fails part way through
+// creating it or any other application that gets created, such as by tests, doesn't affect the one in use
+func (a *application) applyProcessSettings() {
+	setFormattingLocale(a.Config.Server.Locale)
+
+	if level, ok := logLevels[a.Config.Server.LogLevel]; ok {
+		logLevel.Set(level)
+	} else {
+		logLevel.Set(slog.LevelInfo)
+	}
+}
+
 func (a *application) server() (func() error, func() error) {
 	// TODO: add gzip support, static files must have their gzipped contents cached
-	// TODO: add HTTPS support
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("GET /{$}", a.handlePageRequest)
 	mux.HandleFunc("GET /{page}", a.handlePageRequest)
 
 	mux.HandleFunc("GET /api/pages/{page}/content/{$}", a.handlePageContentRequest)
-	mux.HandleFunc("/api/widgets/{widget}/{path...}", a.handleWidgetRequest)
-	mux.HandleFunc("GET /api/healthz", func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
+
+	if !a.isRouteDisabled("/api/widgets") {
+		mux.HandleFunc("/api/widgets/{widget}/{path...}", a.handleWidgetRequest)
+	}
+
+	if a.Config.Server.Metrics.Enabled && !a.isRouteDisabled("/metrics") {
+		mux.HandleFunc("GET /metrics", withBearerToken(a.Config.Server.Metrics.Token, a.handleMetricsRequest))
+	}
+
+	if a.Config.Server.DebugConfig.Enabled && !a.isRouteDisabled("/debug/config") {
+		mux.HandleFunc("GET /debug/config", withBearerToken(a.Config.Server.DebugConfig.Token, a.handleDebugConfigRequest))
+	}
+
+	if !a.isRouteDisabled("/api/healthz") {
+		mux.HandleFunc("GET /api/healthz", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	}
 
 	mux.Handle(
 		fmt.Sprintf("GET /static/%s/{path...}", staticFSHash),
 		http.StripPrefix("/static/"+staticFSHash, fileServerWithCache(http.FS(staticFS), 24*time.Hour)),
 	)
 
-	var absAssetsPath string
-	if a.Config.Server.AssetsPath != "" {
-		absAssetsPath, _ = filepath.Abs(a.Config.Server.AssetsPath)
-		assetsFS := fileServerWithCache(http.Dir(a.Config.Server.AssetsPath), 2*time.Hour)
+	absAssetsPaths := make([]string, len(a.Config.Server.AssetsPath))
+	for i, path := range a.Config.Server.AssetsPath {
+		absAssetsPaths[i], _ = filepath.Abs(path)
+	}
+	absAssetsPath := strings.Join(absAssetsPaths, ", ")
+
+	if len(a.Config.Server.AssetsPath) > 0 {
+		assetsFS := fileServerWithCache(newMultiDirFileSystem(a.Config.Server.AssetsPath), 2*time.Hour)
+		if a.assetFingerprints.Load() != nil {
+			assetsFS = a.handleFingerprintedAssetRequest(assetsFS)
+		}
 		mux.Handle("/assets/{path...}", http.StripPrefix("/assets/", assetsFS))
 	}
 
+	var handler http.Handler = mux
+	if a.Config.Server.DemoMode {
+		handler = withReadOnlyRequests(handler)
+	}
+
+	if len(a.Config.Server.Headers) > 0 {
+		handler = withResponseHeaders(handler, a.Config.Server.Headers)
+	}
+
+	if a.Config.Server.RequestIDHeader != "" {
+		handler = withRequestID(handler, a.Config.Server.RequestIDHeader)
+	}
+
 	server := http.Server{
-		Addr:    fmt.Sprintf("%s:%d", a.Config.Server.Host, a.Config.Server.Port),
-		Handler: mux,
+		Addr:    fmt.Sprintf("%s:%d", a.Config.Server.Host, a.Config.Server.Port.Number),
+		Handler: handler,
+	}
+
+	// stop can be called while start is still setting things up, such as when a reload
+	// replaces the server before it has started listening
+	var mu sync.Mutex
+	var stopped bool
+	var stopWatchingCertificate func() error
+	if a.tlsCertificate != nil {
+		server.TLSConfig = &tls.Config{
+			GetCertificate: a.tlsCertificate.getCertificate,
+		}
 	}
 
 	start := func() error {
-		a.Config.Server.StartedAt = time.Now()
+		a.assetsVersion.Store(time.Now().UnixMilli())
+
+		if a.Config.Server.DemoMode {
+			log.Println("Demo mode is enabled, environment variables are redacted and only read-only requests are allowed")
+		}
+
+		if a.tlsCertificate != nil {
+			log.Printf("Serving over TLS using certificate %s\n", a.tlsCertificate.certFile)
+
+			mu.Lock()
+			if !stopped {
+				var err error
+				stopWatchingCertificate, err = a.tlsCertificate.watch()
+				if err != nil {
+					log.Printf("Error starting TLS certificate watcher, certificate changes will require a restart. (%v)", err)
+				}
+			}
+			mu.Unlock()
+		}
+
+		if a.Config.Server.Socket != "" {
+			log.Printf("Starting server on unix socket %s (base-url: \"%s\", assets-path: \"%s\")\n",
+				a.Config.Server.Socket,
+				a.Config.Server.BaseURL,
+				absAssetsPath,
+			)
+
+			listener, err := a.listenOnUnixSocket()
+			if err != nil {
+				return err
+			}
+
+			// closing the server closes the listener which also removes the socket file
+			if err := a.serve(&server, listener); err != nil && err != http.ErrServerClosed {
+				return err
+			}
+
+			return nil
+		}
+
+		listener, err := net.Listen("tcp", server.Addr)
+		if err != nil {
+			return err
+		}
+
+		// a port of 0 makes the system pick any free port, so the
+		// port that's logged is the one that actually got chosen
 		log.Printf("Starting server on %s:%d (base-url: \"%s\", assets-path: \"%s\")\n",
 			a.Config.Server.Host,
-			a.Config.Server.Port,
+			listener.Addr().(*net.TCPAddr).Port,
 			a.Config.Server.BaseURL,
 			absAssetsPath,
 		)
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := a.serve(&server, listener); err != nil && err != http.ErrServerClosed {
 			return err
 		}
 
 		return nil
 	}
 
+	// stops accepting new connections right away and waits for the requests in progress
+	// to finish, up to the shutdown-timeout, after which their connections get closed
 	stop := func() error {
-		return server.Close()
+		mu.Lock()
+		stopped = true
+		if stopWatchingCertificate != nil {
+			stopWatchingCertificate()
+		}
+		mu.Unlock()
+
+		timeout := a.shutdownTimeout()
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		err := server.Shutdown(ctx)
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Printf("Requests were still in progress after the shutdown timeout of %s, closing their connections", timeout)
+			err = server.Close()
+		}
+
+		a.cancel()
+		if a.widgetCacheBackend != nil {
+			a.widgetCacheBackend.close()
+		}
+
+		return err
 	}
 
 	return start, stop
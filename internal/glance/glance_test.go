@@ -0,0 +1,157 @@
+package glance
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsBaseURLValid(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		valid   bool
+	}{
+		{baseURL: "/dashboard", valid: true},
+		{baseURL: "/dashboard/glance", valid: true},
+		{baseURL: "https://example.com/dashboard", valid: true},
+		{baseURL: "http://example.com", valid: true},
+		{baseURL: "//example.com/dashboard", valid: false},
+		{baseURL: "dashboard", valid: false},
+		{baseURL: "ftp://example.com/dashboard", valid: false},
+		{baseURL: "https:///dashboard", valid: false},
+		{baseURL: "https://example.com/dashboard?page=1", valid: false},
+		{baseURL: "https://example.com/dashboard#top", valid: false},
+	}
+
+	for _, test := range tests {
+		if err := isBaseURLValid(test.baseURL); (err == nil) != test.valid {
+			t.Errorf("isBaseURLValid(%q) = %v, want valid: %v", test.baseURL, err, test.valid)
+		}
+	}
+}
+
+func newBaseURLTestApplication(t *testing.T, baseURL string) *application {
+	t.Helper()
+
+	assetsPath := t.TempDir()
+	for _, name := range []string{"logo.png", "favicon.png", "user.css", "icon.png"} {
+		if err := os.WriteFile(filepath.Join(assetsPath, name), []byte(name), 0o644); err != nil {
+			t.Fatalf("writing asset: %v", err)
+		}
+	}
+
+	contents := `
+server:
+  base-url: ` + baseURL + `
+  assets-path: ` + assetsPath + `
+theme:
+  custom-css-file: /assets/user.css
+branding:
+  logo-url: /assets/logo.png
+  favicon-url: /assets/favicon.png
+pages:
+  - name: Home
+    columns:
+      - size: full
+        widgets:
+          - type: bookmarks
+            groups:
+              - links:
+                  - title: Local
+                    url: https://example.com
+                    icon: /assets/icon.png
+  - name: Second Page
+    slug: second
+    columns:
+      - size: full
+        widgets:
+          - type: clock
+`
+
+	config, err := newConfigFromYAML([]byte(contents))
+	if err != nil {
+		t.Fatalf("parsing config: %v", err)
+	}
+
+	app, err := newApplication(config)
+	if err != nil {
+		t.Fatalf("creating application: %v", err)
+	}
+	t.Cleanup(app.cancel)
+
+	return app
+}
+
+func getFromTestApplication(t *testing.T, app *application, path string) string {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", app.handlePageRequest)
+	mux.HandleFunc("GET /{page}", app.handlePageRequest)
+	mux.HandleFunc("GET /api/pages/{page}/content/{$}", app.handlePageContentRequest)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET %s responded with %d", path, recorder.Code)
+	}
+
+	body, _ := io.ReadAll(recorder.Body)
+	return string(body)
+}
+
+func TestBaseURLIsPrefixedOntoLinks(t *testing.T) {
+	// the trailing slash is removed, otherwise links would contain a double slash
+	app := newBaseURLTestApplication(t, "/dashboard/")
+
+	if app.Config.Server.BaseURL != "/dashboard" {
+		t.Fatalf("base URL = %q, want /dashboard", app.Config.Server.BaseURL)
+	}
+
+	page := getFromTestApplication(t, app, "/")
+	staticPrefix := "/dashboard/static/" + staticFSHash + "/"
+
+	for name, want := range map[string]string{
+		"stylesheet":         `href="` + staticPrefix + "main.css",
+		"script":             `src="` + staticPrefix + "js/main.js",
+		"manifest":           `href="` + staticPrefix + "manifest.json",
+		"favicon":            `href="/dashboard/assets/favicon.png"`,
+		"logo":               `src="/dashboard/assets/logo.png"`,
+		"custom css file":    `href="/dashboard/assets/user.css`,
+		"link to first page": `href="/dashboard/home"`,
+		"link to next page":  `href="/dashboard/second"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("%s: page does not contain %s", name, want)
+		}
+	}
+
+	if strings.Contains(page, `"/static/`) || strings.Contains(page, `"/assets/`) {
+		t.Error("page contains a static or user asset URL without the base URL")
+	}
+
+	content := getFromTestApplication(t, app, "/api/pages/home/content/")
+	if !strings.Contains(content, `src="/dashboard/assets/icon.png"`) {
+		t.Error("bookmark icon from the assets path does not have the base URL")
+	}
+}
+
+func TestFullBaseURLIsPrefixedOntoLinks(t *testing.T) {
+	app := newBaseURLTestApplication(t, "https://example.com/dashboard")
+	page := getFromTestApplication(t, app, "/second")
+
+	for _, want := range []string{
+		`href="https://example.com/dashboard/static/` + staticFSHash + "/main.css",
+		`href="https://example.com/dashboard/assets/favicon.png"`,
+		`href="https://example.com/dashboard/home"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page does not contain %s", want)
+		}
+	}
+}
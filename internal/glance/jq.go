@@ -0,0 +1,73 @@
+package glance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+)
+
+// Used for transforming JSON responses before they reach the template
+type jqQuery struct {
+	code *gojq.Code
+}
+
+func parseJQQuery(query string) (*jqQuery, error) {
+	parsed, err := gojq.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// environment variables aren't available through $ENV or env since no loader is given
+	code, err := gojq.Compile(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jqQuery{code: code}, nil
+}
+
+// Applies the query to the JSON and returns the result encoded as JSON. Since the template
+// needs a single JSON document rather than the stream of values jq would output, expressions
+// that produce more than one value have them collected into an array, while ones that produce
+// no value at all result in null.
+func (q *jqQuery) apply(ctx context.Context, input []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %v", err)
+	}
+
+	var results []any
+	iter := q.code.RunWithContext(ctx, value)
+
+	for {
+		result, ok := iter.Next()
+		if !ok {
+			break
+		}
+
+		if err, ok := result.(error); ok {
+			if err, ok := err.(*gojq.HaltError); ok && err.Value() == nil {
+				break
+			}
+
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	switch len(results) {
+	case 0:
+		return json.Marshal(nil)
+	case 1:
+		return json.Marshal(results[0])
+	default:
+		return json.Marshal(results)
+	}
+}
@@ -0,0 +1,105 @@
+package glance
+
+import (
+	"context"
+	"testing"
+)
+
+// The expected outputs are the ones jq itself gives (jq -c) for the same query and input,
+// except that multiple outputs get collected into an array, the keys of objects are sorted
+// and large integers keep their precision rather than becoming floats
+func TestJQQueryMatchesJQ(t *testing.T) {
+	tests := []struct {
+		query string
+		input string
+		want  string
+	}{
+		{query: ".", input: `{"a":1}`, want: `{"a":1}`},
+		{query: ".a.b", input: `{"a":{"b":1}}`, want: `1`},
+		{query: `."a-b"`, input: `{"a-b":"x"}`, want: `"x"`},
+		{query: ".[-1]", input: `[1,2,3]`, want: `3`},
+		{query: ".missing", input: `{"a":1}`, want: `null`},
+		{query: ".a", input: `{"a":1.50}`, want: `1.5`},
+		{query: ".a", input: `{"a":12345678901234567890}`, want: `12345678901234567890`},
+		{query: "[.items[].title]", input: `{"items":[{"title":"x"},{"title":"y"}]}`, want: `["x","y"]`},
+		{query: ".items[].title", input: `{"items":[{"title":"x"},{"title":"y"}]}`, want: `["x","y"]`},
+		{query: ".items[].title", input: `{"items":[{"title":"x"}]}`, want: `"x"`},
+		{query: ".items[].title", input: `{"items":[]}`, want: `null`},
+		{query: ".a, .b", input: `{"a":1,"b":2}`, want: `[1,2]`},
+		{query: "[.[] | select(.n > 1) | .n]", input: `[{"n":1},{"n":2},{"n":3}]`, want: `[2,3]`},
+		{query: "map(.n) | add", input: `[{"n":1},{"n":2}]`, want: `3`},
+		{query: "{title: .data.title, count: (.data.items | length)}", input: `{"data":{"title":"t","items":[1,2]}}`, want: `{"count":2,"title":"t"}`},
+		{query: "$ENV | length", input: `null`, want: `0`},
+	}
+
+	for _, test := range tests {
+		query, err := parseJQQuery(test.query)
+		if err != nil {
+			t.Errorf("parsing %s: %v", test.query, err)
+			continue
+		}
+
+		got, err := query.apply(context.Background(), []byte(test.input))
+		if err != nil {
+			t.Errorf("applying %s to %s: %v", test.query, test.input, err)
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("applying %s to %s = %s, want %s", test.query, test.input, got, test.want)
+		}
+	}
+}
+
+func TestJQQueryParseErrors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		".[",
+		".a | ",
+		"[.a",
+		"unknown_function",
+	} {
+		if _, err := parseJQQuery(query); err == nil {
+			t.Errorf("parsing %q: expected an error", query)
+		}
+	}
+}
+
+func TestJQQueryApplyErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		input string
+	}{
+		// jq: Cannot index array with string "a"
+		{query: ".a", input: `[1]`},
+		// jq: Cannot iterate over string ("s")
+		{query: "[.[]]", input: `"s"`},
+		{query: `error("failed")`, input: `null`},
+		{query: ".a", input: `not json`},
+	}
+
+	for _, test := range tests {
+		query, err := parseJQQuery(test.query)
+		if err != nil {
+			t.Fatalf("parsing %s: %v", test.query, err)
+		}
+
+		if got, err := query.apply(context.Background(), []byte(test.input)); err == nil {
+			t.Errorf("applying %s to %s: expected an error, got %s", test.query, test.input, got)
+		}
+	}
+}
+
+func TestJQQueryStopsWithContext(t *testing.T) {
+	query, err := parseJQQuery("repeat(.)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := query.apply(ctx, []byte(`1`)); err == nil {
+		t.Error("expected an error once the context is done")
+	}
+}
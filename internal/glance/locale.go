@@ -0,0 +1,97 @@
+package glance
+
+import (
+	"log"
+	"sync/atomic"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Used when server.locale isn't set, which matches how numbers were formatted before it existed
+var defaultLocale = language.English
+
+// Swapped whenever a config gets loaded, since templates are parsed only once and share it
+var localePrinter atomic.Pointer[message.Printer]
+
+func init() {
+	localePrinter.Store(message.NewPrinter(defaultLocale))
+}
+
+func intl() *message.Printer {
+	return localePrinter.Load()
+}
+
+func setFormattingLocale(locale string) {
+	localePrinter.Store(message.NewPrinter(localeTag(locale)))
+}
+
+// Returns the locale in its canonical form, or an empty string along with a
+// warning if it isn't a valid tag so that the defaults get used instead
+func normalizeLocale(locale string) string {
+	if locale == "" {
+		return ""
+	}
+
+	tag, err := language.Parse(locale)
+	if err != nil {
+		log.Printf("Warning: server locale %s is not a valid locale such as en-GB or de-DE, using the default locale instead: %v", locale, err)
+		return ""
+	}
+
+	return tag.String()
+}
+
+// The locale should already be normalized
+func localeTag(locale string) language.Tag {
+	if locale == "" {
+		return defaultLocale
+	}
+
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return defaultLocale
+	}
+
+	return tag
+}
+
+// Regions where time is usually written using a 12 hour clock, the rest default to 24 hours
+var twelveHourClockRegions = map[string]bool{
+	"US": true, "CA": true, "AU": true, "NZ": true, "IN": true, "PH": true,
+	"PK": true, "BD": true, "EG": true, "SA": true, "KR": true, "CO": true,
+}
+
+// Regions which aren't part of the locale, such as with just de, are the most likely ones for the language
+func localeHourFormat(locale string) string {
+	region, _ := localeTag(locale).Region()
+	if twelveHourClockRegions[region.String()] {
+		return "12h"
+	}
+
+	return "24h"
+}
+
+// Only sets the hour format of the widgets which don't have one of their own
+func applyLocaleHourFormat(list widgets, hourFormat string) {
+	for _, widget := range list {
+		switch widget := widget.(type) {
+		case *clockWidget:
+			if widget.HourFormat == "" {
+				widget.HourFormat = hourFormat
+			}
+		case *weatherWidget:
+			if widget.HourFormat == "" {
+				widget.HourFormat = hourFormat
+			}
+		case *dnsStatsWidget:
+			if widget.HourFormat == "" {
+				widget.HourFormat = hourFormat
+			}
+		}
+
+		if container, ok := widget.(widgetContainer); ok {
+			applyLocaleHourFormat(container.containedWidgets(), hourFormat)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package glance
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// The level of the slog handler set up in Main, changed by the log-level of the server
+var logLevel slog.LevelVar
+
+// Writes lines in the same format as the default slog handler, "2006/01/02 15:04:05 INFO message key=value",
+// along with the ID of the request the line was logged with the context of, if it has one. It writes directly
+// to its output rather than through the log package, which lets it be set as the default handler without
+// the log package and slog writing through each other
+type logHandler struct {
+	mu     *sync.Mutex
+	output io.Writer
+	level  slog.Leveler
+	attrs  []byte
+	prefix string
+}
+
+func newLogHandler(output io.Writer, level slog.Leveler) *logHandler {
+	return &logHandler{
+		mu:     &sync.Mutex{},
+		output: output,
+		level:  level,
+	}
+}
+
+func (h *logHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	recordTime := record.Time
+	if recordTime.IsZero() {
+		recordTime = time.Now()
+	}
+
+	line := make([]byte, 0, 256)
+	line = recordTime.AppendFormat(line, "2006/01/02 15:04:05 ")
+	line = append(line, record.Level.String()...)
+	line = append(line, ' ')
+	line = append(line, record.Message...)
+	line = append(line, h.attrs...)
+	record.Attrs(func(attr slog.Attr) bool {
+		line = appendLogAttr(line, h.prefix, attr)
+		return true
+	})
+
+	if id := requestIDFromContext(ctx); id != "" {
+		line = appendLogAttr(line, "", slog.String("request_id", id))
+	}
+
+	line = append(line, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err := h.output.Write(line)
+	return err
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handler := *h
+	handler.attrs = slices.Clip(h.attrs)
+
+	for _, attr := range attrs {
+		handler.attrs = appendLogAttr(handler.attrs, h.prefix, attr)
+	}
+
+	return &handler
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	handler := *h
+	handler.prefix = h.prefix + name + "."
+
+	return &handler
+}
+
+func appendLogAttr(line []byte, prefix string, attr slog.Attr) []byte {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return line
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+
+		for _, groupAttr := range attr.Value.Group() {
+			line = appendLogAttr(line, prefix, groupAttr)
+		}
+
+		return line
+	}
+
+	line = append(line, ' ')
+	line = append(line, prefix...)
+	line = append(line, attr.Key...)
+	line = append(line, '=')
+
+	value := attr.Value.String()
+	if value == "" || strings.ContainsFunc(value, func(r rune) bool {
+		return r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r)
+	}) {
+		return strconv.AppendQuote(line, value)
+	}
+
+	return append(line, value...)
+}
@@ -0,0 +1,31 @@
+package glance
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogHandler(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(newLogHandler(&output, slog.LevelInfo))
+
+	logger.Debug("not logged")
+	logger.With("widget", "rss").WithGroup("feed").ErrorContext(
+		contextWithRequestID(context.Background(), "abc"),
+		"Failed to get RSS feed", "url", "https://example.com", "error", "status 404 Not Found",
+	)
+
+	// the date and time at the start of the line differ between runs
+	got := output.String()
+	want := ` ERROR Failed to get RSS feed widget=rss feed.url=https://example.com feed.error="status 404 Not Found" request_id=abc` + "\n"
+	if len(got) < 19 || got[19:] != want {
+		t.Fatalf("got %q, want a timestamp followed by %q", got, want)
+	}
+
+	if strings.Count(got, "\n") != 1 {
+		t.Fatalf("expected a single line, got %q", got)
+	}
+}
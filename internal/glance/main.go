@@ -1,16 +1,34 @@
 package glance
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
 )
 
 var buildVersion = "dev"
 
 func Main() int {
+	setupLogging()
+
 	options, err := parseCliOptions()
 	if err != nil {
 		fmt.Println(err)
@@ -20,16 +38,16 @@ func Main() int {
 	switch options.intent {
 	case cliIntentServe:
 		// remove in v0.10.0
-		if serveUpdateNoticeIfConfigLocationNotMigrated(options.configPath) {
+		if !options.configFromEnv && serveUpdateNoticeIfConfigLocationNotMigrated(options.configPath) {
 			return 1
 		}
 
-		if err := serveApp(options.configPath); err != nil {
+		if err := serveApp(options); err != nil {
 			fmt.Println(err)
 			return 1
 		}
 	case cliIntentConfigValidate:
-		contents, _, err := parseYAMLIncludes(options.configPath)
+		contents, _, err := readConfigContents(options)
 		if err != nil {
 			fmt.Printf("Could not parse config file: %v\n", err)
 			return 1
@@ -40,7 +58,7 @@ func Main() int {
 			return 1
 		}
 	case cliIntentConfigPrint:
-		contents, _, err := parseYAMLIncludes(options.configPath)
+		contents, _, err := readConfigContents(options)
 		if err != nil {
 			fmt.Printf("Could not parse config file: %v\n", err)
 			return 1
@@ -54,17 +72,60 @@ func Main() int {
 	return 0
 }
 
-func serveApp(configPath string) error {
+func readConfigContents(options *cliOptions) ([]byte, map[string]struct{}, error) {
+	if !options.configFromEnv {
+		return parseYAMLIncludes(options.configPath)
+	}
+
+	contents := []byte(os.Getenv(configEnvVariableName))
+	if includePattern.Match(contents) || includeIfPattern.Match(contents) ||
+		includeIfExistsPattern.Match(contents) || includeMergePattern.Match(contents) ||
+		includeDirPattern.Match(contents) || includeRawPattern.Match(contents) {
+		return nil, nil, fmt.Errorf("!include is not supported when the config is read from %s", configEnvVariableName)
+	}
+
+	return contents, make(map[string]struct{}), nil
+}
+
+// Lines logged through slog with the context of a request include its ID, while the log package,
+// which slog.SetDefault would otherwise route through slog at info level, keeps writing its own lines
+func setupLogging() {
+	slog.SetDefault(slog.New(newLogHandler(os.Stderr, &logLevel)))
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags)
+}
+
+func serveApp(options *cliOptions) error {
 	exitChannel := make(chan struct{})
 	hadValidConfigOnStartup := false
 	var stopServer func() error
-
-	onChange := func(newContents []byte) {
-		if stopServer != nil {
-			log.Println("Config file changed, reloading...")
+	// closed once the previous server has been stopped, which is when the next one can start
+	var serverSwapped chan struct{}
+	// reasons for reloading the config that the file watcher can't detect on its own
+	reloadRequests := make(chan string, 1)
+	var stopPollingEnvVars func()
+	var stopWatchingAssets func()
+
+	// used to enforce the hot-reload-delay, reloads that happen during the delay get queued until
+	// it's over, at which point only the most recent contents get used since they include all changes
+	reloadMu := sync.Mutex{}
+	var hotReloadDelay time.Duration
+	var lastReloadAt time.Time
+	var queuedReload *time.Timer
+	var queuedContents []byte
+	var queuedTrigger string
+
+	reload := func(newContents []byte, trigger string) {
+		defer func() { lastReloadAt = time.Now() }()
+
+		isReload := stopServer != nil
+		if isReload {
+			log.Printf("%s, reloading...", trigger)
 		}
 
+		parseStartedAt := time.Now()
 		config, err := newConfigFromYAML(newContents)
+		slog.Debug(fmt.Sprintf("Config parsing took %dms, including replacing variables and initializing widgets", time.Since(parseStartedAt).Milliseconds()))
 		if err != nil {
 			log.Printf("Config has errors: %v", err)
 
@@ -77,63 +138,419 @@ func serveApp(configPath string) error {
 			hadValidConfigOnStartup = true
 		}
 
+		hotReloadDelay = time.Duration(config.Server.HotReloadDelay)
+
 		app, err := newApplication(config)
 		if err != nil {
 			log.Printf("Failed to create application: %v", err)
 			return
 		}
 
-		if stopServer != nil {
-			if err := stopServer(); err != nil {
-				log.Printf("Error while trying to stop server: %v", err)
+		if stopPollingEnvVars != nil {
+			stopPollingEnvVars()
+			stopPollingEnvVars = nil
+		}
+
+		if len(config.Server.WatchEnvVars) > 0 {
+			interval := time.Duration(config.Server.EnvPollInterval)
+			if interval == 0 {
+				interval = defaultEnvPollInterval
 			}
+
+			stopPollingEnvVars = pollEnvVars(config.Server.WatchEnvVars, interval, reloadRequests)
+		}
+
+		if stopWatchingAssets != nil {
+			stopWatchingAssets()
+			stopWatchingAssets = nil
 		}
 
+		if len(config.Server.AssetsPath) > 0 {
+			stopWatchingAssets, err = watchAssetsPaths(config.Server.AssetsPath, app.refreshAssets)
+			if err != nil {
+				log.Printf("Error watching assets-path, changes to assets will require a reload: %v", err)
+			}
+		}
+
+		app.applyProcessSettings()
+
+		startServer, stop := app.server()
+		previousStop, previousSwapped := stopServer, serverSwapped
+		swapped := make(chan struct{})
+		stopServer, serverSwapped = stop, swapped
+
+		// stopping the previous server can take up to its shutdown-timeout, so it's done without holding
+		// reloadMu, while still swapping servers one at a time in the order that the reloads happened
 		go func() {
-			var startServer func() error
-			startServer, stopServer = app.server()
+			if previousSwapped != nil {
+				<-previousSwapped
+			}
+
+			if previousStop != nil {
+				if err := previousStop(); err != nil {
+					log.Printf("Error while trying to stop server: %v", err)
+				}
+			}
+
+			close(swapped)
 
 			if err := startServer(); err != nil {
 				log.Printf("Failed to start server: %v", err)
 			}
 		}()
+
+		if config.Server.StartupCheck {
+			go app.runStartupCheck()
+		}
+
+		if isReload {
+			metrics.observeConfigReload()
+		}
+
+		if isReload && config.Server.HotReloadWebhook != "" {
+			go notifyHotReloadWebhook(config.Server.HotReloadWebhook, newContents, len(config.Pages))
+		}
+	}
+
+	onChange := func(newContents []byte, trigger string) {
+		reloadMu.Lock()
+		defer reloadMu.Unlock()
+
+		if queuedReload != nil {
+			queuedContents, queuedTrigger = newContents, trigger
+			return
+		}
+
+		remaining := hotReloadDelay - time.Since(lastReloadAt)
+		if stopServer == nil || remaining <= 0 {
+			reload(newContents, trigger)
+			return
+		}
+
+		log.Printf("%s, reloading in %s because of the hot-reload-delay", trigger, remaining.Round(time.Millisecond))
+		queuedContents, queuedTrigger = newContents, trigger
+		queuedReload = time.AfterFunc(remaining, func() {
+			reloadMu.Lock()
+			defer reloadMu.Unlock()
+
+			queuedReload = nil
+			reload(queuedContents, queuedTrigger)
+		})
 	}
 
 	onErr := func(err error) {
 		log.Printf("Error watching config files: %v", err)
 	}
 
-	configContents, configIncludes, err := parseYAMLIncludes(configPath)
+	configContents, configIncludes, err := readConfigContents(options)
 	if err != nil {
 		return fmt.Errorf("parsing config: %w", err)
 	}
 
-	stopWatching, err := configFilesWatcher(configPath, configContents, configIncludes, onChange, onErr)
-	if err == nil {
-		defer stopWatching()
-	} else {
+	if options.configFromEnv {
+		// there's nothing to watch, changes to the variable require a restart
+		return startAppWithoutWatcher(configContents)
+	}
+
+	stopWatching, err := configFilesWatcher(options.configPath, configContents, configIncludes, onChange, onErr, reloadRequests)
+	if err != nil {
 		log.Printf("Error starting file watcher, config file changes will require a manual restart. (%v)", err)
+		return startAppWithoutWatcher(configContents)
+	}
+	defer stopWatching()
 
-		config, err := newConfigFromYAML(configContents)
-		if err != nil {
-			return fmt.Errorf("validating config file: %w", err)
+	stopHandlingReloadSignal := requestReloadOnSignal(reloadRequests)
+	defer stopHandlingReloadSignal()
+	defer func() {
+		if stopPollingEnvVars != nil {
+			stopPollingEnvVars()
 		}
 
-		app, err := newApplication(config)
-		if err != nil {
-			return fmt.Errorf("creating application: %w", err)
+		if stopWatchingAssets != nil {
+			stopWatchingAssets()
 		}
+	}()
+	defer func() {
+		reloadMu.Lock()
+		defer reloadMu.Unlock()
+
+		if queuedReload != nil {
+			queuedReload.Stop()
+			queuedReload = nil
+		}
+	}()
+
+	select {
+	case <-exitChannel:
+	case <-shutdownSignal():
+		log.Println("Shutting down...")
 
-		startServer, _ := app.server()
-		if err := startServer(); err != nil {
-			return fmt.Errorf("starting server: %w", err)
+		reloadMu.Lock()
+		stop := stopServer
+		reloadMu.Unlock()
+
+		if stop != nil {
+			if err := stop(); err != nil {
+				log.Printf("Error while trying to stop server: %v", err)
+			}
 		}
 	}
 
-	<-exitChannel
 	return nil
 }
 
+var hotReloadWebhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// Sent once per successful reload, failures only get logged since the
+// webhook is informational and retrying could flood the receiver
+func notifyHotReloadWebhook(webhookURL string, configContents []byte, pageCount int) {
+	hash := sha256.Sum256(configContents)
+	body, err := json.Marshal(map[string]any{
+		"reloaded_at": time.Now().UTC().Format(time.RFC3339),
+		"config_hash": hex.EncodeToString(hash[:]),
+		"page_count":  pageCount,
+	})
+	if err != nil {
+		log.Printf("Failed to encode hot reload webhook body: %v", err)
+		return
+	}
+
+	response, err := hotReloadWebhookClient.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// webhook URLs often contain tokens, avoid logging them
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		log.Printf("Failed to send hot reload webhook: %v", err)
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		log.Printf("Hot reload webhook responded with unexpected status code %d", response.StatusCode)
+	}
+}
+
+func shutdownSignal() <-chan os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	return signals
+}
+
+// Allows reloading the config with SIGHUP, for when changes to the config
+// files can't be detected, such as on some network mounts
+func requestReloadOnSignal(reloadRequests chan<- string) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for range signals {
+			select {
+			case reloadRequests <- "Received SIGHUP":
+			default:
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(signals)
+	}
+}
+
+const defaultEnvPollInterval = 60 * time.Second
+
+// Changing environment variables doesn't produce any filesystem events, so the
+// watched ones get checked periodically and the config watcher gets notified
+// through reloadRequests whenever any of them has a different value than before
+func pollEnvVars(names []string, interval time.Duration, reloadRequests chan<- string) func() {
+	lastValues := make(map[string]string, len(names))
+	for _, name := range names {
+		lastValues[name] = os.Getenv(name)
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			var changed []string
+			for _, name := range names {
+				if value := os.Getenv(name); value != lastValues[name] {
+					lastValues[name] = value
+					changed = append(changed, name)
+				}
+			}
+
+			if len(changed) == 0 {
+				continue
+			}
+
+			select {
+			case reloadRequests <- fmt.Sprintf("Environment variable %s changed", strings.Join(changed, ", ")):
+			default:
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+// Calls onChange whenever a file within any of the paths changes, so that the asset fingerprints
+// and the version appended to asset URLs can be updated in order for browsers to pick up the new
+// version. Directories within the paths are watched individually since fsnotify doesn't watch
+// them recursively.
+func watchAssetsPaths(paths []string, onChange func()) (func(), error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating watcher: %w", err)
+	}
+
+	watchDir := func(root string) error {
+		return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				return watcher.Add(path)
+			}
+
+			return nil
+		})
+	}
+
+	for _, path := range paths {
+		if err := watchDir(path); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("watching %s: %w", path, err)
+		}
+	}
+
+	const debounceDuration = 500 * time.Millisecond
+	var debounceTimer *time.Timer
+	var changedPath string
+	mu := sync.Mutex{}
+
+	notifyChange := func() {
+		mu.Lock()
+		log.Printf("Asset %s changed, updating asset URLs", changedPath)
+		mu.Unlock()
+
+		onChange()
+	}
+
+	go func() {
+		for {
+			select {
+			case event, isOpen := <-watcher.Events:
+				if !isOpen {
+					return
+				}
+
+				if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+					continue
+				}
+
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if err := watchDir(event.Name); err != nil {
+							log.Printf("Could not watch new assets directory %s: %v", event.Name, err)
+						}
+					}
+				}
+
+				mu.Lock()
+				changedPath = event.Name
+				if debounceTimer != nil {
+					debounceTimer.Reset(debounceDuration)
+				} else {
+					debounceTimer = time.AfterFunc(debounceDuration, notifyChange)
+				}
+				mu.Unlock()
+			case err, isOpen := <-watcher.Errors:
+				if !isOpen {
+					return
+				}
+
+				log.Printf("Error watching assets-path: %v", err)
+			}
+		}
+	}()
+
+	return func() {
+		watcher.Close()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if debounceTimer != nil {
+			debounceTimer.Stop()
+		}
+	}, nil
+}
+
+func startAppWithoutWatcher(configContents []byte) error {
+	config, err := newConfigFromYAML(configContents)
+	if err != nil {
+		return fmt.Errorf("validating config file: %w", err)
+	}
+
+	app, err := newApplication(config)
+	if err != nil {
+		return fmt.Errorf("creating application: %w", err)
+	}
+
+	app.applyProcessSettings()
+
+	startServer, stopServer := app.server()
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- startServer()
+	}()
+
+	// there's nothing that could be reloaded, but SIGHUP would otherwise terminate the process
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	defer signal.Stop(reloadSignals)
+
+	shutdownSignals := shutdownSignal()
+
+	for {
+		select {
+		case err := <-serverErr:
+			if err != nil {
+				return fmt.Errorf("starting server: %w", err)
+			}
+
+			return nil
+		case <-reloadSignals:
+			log.Println("Received SIGHUP, ignoring it since the config can only be reloaded when its files are being watched")
+		case <-shutdownSignals:
+			log.Println("Shutting down...")
+			if err := stopServer(); err != nil {
+				return fmt.Errorf("stopping server: %w", err)
+			}
+
+			return nil
+		}
+	}
+}
+
 func serveUpdateNoticeIfConfigLocationNotMigrated(configPath string) bool {
 	if !isRunningInsideDockerContainer() {
 		return false
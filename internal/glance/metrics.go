@@ -0,0 +1,183 @@
+package glance
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics are kept globally rather than per application so that
+// they don't get reset when the config gets reloaded
+var metrics = newMetricsRegistry()
+
+var widgetFetchDurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type widgetMetricsLabels struct {
+	widgetType string
+	page       string
+}
+
+type widgetFetchMetrics struct {
+	fetches      uint64
+	errors       uint64
+	durationSum  float64
+	bucketCounts []uint64 // same length as widgetFetchDurationBuckets, not cumulative
+}
+
+type metricsRegistry struct {
+	mu                 sync.Mutex
+	widgetFetches      map[widgetMetricsLabels]*widgetFetchMetrics
+	configReloads      uint64
+	lastConfigReloadAt time.Time
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		widgetFetches: make(map[widgetMetricsLabels]*widgetFetchMetrics),
+	}
+}
+
+func (m *metricsRegistry) observeWidgetFetch(widgetType, page string, duration time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	labels := widgetMetricsLabels{widgetType: widgetType, page: page}
+	fetch, exists := m.widgetFetches[labels]
+	if !exists {
+		fetch = &widgetFetchMetrics{bucketCounts: make([]uint64, len(widgetFetchDurationBuckets))}
+		m.widgetFetches[labels] = fetch
+	}
+
+	fetch.fetches++
+	if failed {
+		fetch.errors++
+	}
+
+	seconds := duration.Seconds()
+	fetch.durationSum += seconds
+
+	for i, bound := range widgetFetchDurationBuckets {
+		if seconds <= bound {
+			fetch.bucketCounts[i]++
+			break
+		}
+	}
+}
+
+func (m *metricsRegistry) observeConfigReload() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.configReloads++
+	m.lastConfigReloadAt = time.Now()
+}
+
+// Writes all metrics in the Prometheus text exposition format
+func (m *metricsRegistry) writeTo(w *strings.Builder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	labels := make([]widgetMetricsLabels, 0, len(m.widgetFetches))
+	for l := range m.widgetFetches {
+		labels = append(labels, l)
+	}
+
+	slices.SortFunc(labels, func(a, b widgetMetricsLabels) int {
+		if c := strings.Compare(a.page, b.page); c != 0 {
+			return c
+		}
+		return strings.Compare(a.widgetType, b.widgetType)
+	})
+
+	formatLabels := func(l widgetMetricsLabels) string {
+		return fmt.Sprintf(`type="%s",page="%s"`, escapeMetricsLabelValue(l.widgetType), escapeMetricsLabelValue(l.page))
+	}
+
+	w.WriteString("# HELP glance_widget_fetches_total Number of times widgets have fetched their data.\n")
+	w.WriteString("# TYPE glance_widget_fetches_total counter\n")
+	for _, l := range labels {
+		fmt.Fprintf(w, "glance_widget_fetches_total{%s} %d\n", formatLabels(l), m.widgetFetches[l].fetches)
+	}
+
+	w.WriteString("# HELP glance_widget_fetch_errors_total Number of widget fetches which resulted in an error.\n")
+	w.WriteString("# TYPE glance_widget_fetch_errors_total counter\n")
+	for _, l := range labels {
+		fmt.Fprintf(w, "glance_widget_fetch_errors_total{%s} %d\n", formatLabels(l), m.widgetFetches[l].errors)
+	}
+
+	w.WriteString("# HELP glance_widget_fetch_duration_seconds How long widget fetches took.\n")
+	w.WriteString("# TYPE glance_widget_fetch_duration_seconds histogram\n")
+	for _, l := range labels {
+		fetch := m.widgetFetches[l]
+		var cumulative uint64
+
+		for i, bound := range widgetFetchDurationBuckets {
+			cumulative += fetch.bucketCounts[i]
+			fmt.Fprintf(w, "glance_widget_fetch_duration_seconds_bucket{%s,le=\"%g\"} %d\n", formatLabels(l), bound, cumulative)
+		}
+
+		fmt.Fprintf(w, "glance_widget_fetch_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", formatLabels(l), fetch.fetches)
+		fmt.Fprintf(w, "glance_widget_fetch_duration_seconds_sum{%s} %g\n", formatLabels(l), fetch.durationSum)
+		fmt.Fprintf(w, "glance_widget_fetch_duration_seconds_count{%s} %d\n", formatLabels(l), fetch.fetches)
+	}
+
+	w.WriteString("# HELP glance_config_reloads_total Number of successful config reloads since startup.\n")
+	w.WriteString("# TYPE glance_config_reloads_total counter\n")
+	fmt.Fprintf(w, "glance_config_reloads_total %d\n", m.configReloads)
+
+	w.WriteString("# HELP glance_config_last_reload_timestamp_seconds When the config was last successfully reloaded, 0 if it hasn't been.\n")
+	w.WriteString("# TYPE glance_config_last_reload_timestamp_seconds gauge\n")
+	if m.lastConfigReloadAt.IsZero() {
+		w.WriteString("glance_config_last_reload_timestamp_seconds 0\n")
+	} else {
+		fmt.Fprintf(w, "glance_config_last_reload_timestamp_seconds %d\n", m.lastConfigReloadAt.Unix())
+	}
+}
+
+func escapeMetricsLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func (a *application) handleMetricsRequest(w http.ResponseWriter, r *http.Request) {
+	var body strings.Builder
+	metrics.writeTo(&body)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(body.String()))
+}
+
+// Only lets requests through if they have an `Authorization: Bearer <token>` header
+// with the given token, does nothing if the token is empty
+func withBearerToken(token string, handler http.HandlerFunc) http.HandlerFunc {
+	if token == "" {
+		return handler
+	}
+
+	expected := []byte("Bearer " + token)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+type pageSlugContextKey struct{}
+
+func contextWithPageSlug(ctx context.Context, slug string) context.Context {
+	return context.WithValue(ctx, pageSlugContextKey{}, slug)
+}
+
+func pageSlugFromContext(ctx context.Context) string {
+	slug, _ := ctx.Value(pageSlugContextKey{}).(string)
+	return slug
+}
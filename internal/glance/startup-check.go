@@ -0,0 +1,92 @@
+package glance
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Updates all widgets right away rather than waiting for their pages to be opened and
+// logs which of them failed to fetch their data, so that things like a mistyped URL
+// or a revoked API key show up in the logs. Failures don't stop Glance from starting.
+func (a *application) runStartupCheck() {
+	startedAt := time.Now()
+	var checked, partial, failed int
+
+	for p := range a.Config.Pages {
+		page := &a.Config.Pages[p]
+
+		func() {
+			page.mu.Lock()
+			defer page.mu.Unlock()
+
+			now := time.Now()
+			var fetching []widget
+
+			for c := range page.Columns {
+				fetching = append(fetching, widgetsMissingFirstUpdate(page.Columns[c].Widgets, &now)...)
+			}
+
+			if len(fetching) == 0 {
+				return
+			}
+
+			page.updateOutdatedWidgets(a.ctx, allPageColumns(page), nil)
+
+			checked += len(fetching)
+
+			for _, widget := range fetching {
+				if err := widget.getError(); err != nil {
+					failed++
+					log.Printf("Startup check: %s could not fetch its data: %v", describeStartupCheckWidget(page, widget), err)
+				} else if notice := widget.getNotice(); notice != nil {
+					partial++
+					log.Printf("Startup check: %s fetched only part of its data: %v", describeStartupCheckWidget(page, widget), notice)
+				}
+			}
+		}()
+	}
+
+	log.Printf(
+		"Startup check finished in %s: %d of %d widgets fetched their data, %d partially and %d failed",
+		time.Since(startedAt).Round(time.Millisecond),
+		checked-failed-partial, checked, partial, failed,
+	)
+}
+
+// Returns the widgets which fetch data and haven't done so yet, going through the widgets
+// of containers rather than the containers themselves since each one fetches separately
+func widgetsMissingFirstUpdate(list widgets, now *time.Time) []widget {
+	var result []widget
+
+	for _, widget := range list {
+		if container, ok := widget.(widgetContainer); ok {
+			result = append(result, widgetsMissingFirstUpdate(container.containedWidgets(), now)...)
+			continue
+		}
+
+		if widget.isAwaitingFirstUpdate() && widget.requiresUpdate(now) {
+			result = append(result, widget)
+		}
+	}
+
+	return result
+}
+
+// Includes the columns that aren't active today, so that they get checked too
+func allPageColumns(page *page) []*pageColumn {
+	columns := make([]*pageColumn, len(page.Columns))
+	for c := range page.Columns {
+		columns[c] = &page.Columns[c]
+	}
+
+	return columns
+}
+
+func describeStartupCheckWidget(page *page, widget widget) string {
+	if title := widget.GetTitle(); title != "" {
+		return fmt.Sprintf("%s widget %q on page %s", widget.GetType(), title, page.Title)
+	}
+
+	return fmt.Sprintf("%s widget on page %s", widget.GetType(), page.Title)
+}
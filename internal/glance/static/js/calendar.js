@@ -1,5 +1,6 @@
 import { directions, easeOutQuint, slideFade } from "./animations.js";
 import { elem, repeat, text } from "./templating.js";
+import { configuredLocale } from "./utils.js";
 
 const FULL_MONTH_SLOTS = 7*6;
 const WEEKDAY_ABBRS = ["Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"];
@@ -111,7 +112,9 @@ function Header(nextClicked, prevClicked, undoClicked) {
         monthSwitcher
     ).component({
         update: function (now, newDate) {
-            month.text(MONTH_NAMES[newDate.getMonth()]);
+            month.text(configuredLocale
+                ? newDate.toLocaleDateString(configuredLocale, { month: "long" })
+                : MONTH_NAMES[newDate.getMonth()]);
             year.text(newDate.getFullYear());
             const m = newDate.getMonth() + 1;
             monthNumber.text((m < 10 ? "0" : "") + m);
@@ -182,7 +185,7 @@ function Dates(firstDay) {
     return elem().append(
         elem().classes("calendar-dates", "margin-top-15").append(
             ...repeat(7, (i) => elem().classes("size-h6", "color-subdue").text(
-                WEEKDAY_ABBRS[(firstDay + i) % 7]
+                weekdayAbbr((firstDay + i) % 7)
             ))
         ),
 
@@ -192,6 +195,13 @@ function Dates(firstDay) {
     ).component({ update });
 }
 
+function weekdayAbbr(day) {
+    if (!configuredLocale) return WEEKDAY_ABBRS[day];
+
+    // the 1st of January 2023 was a Sunday, which is day 0
+    return new Date(2023, 0, 1 + day).toLocaleDateString(configuredLocale, { weekday: "short" });
+}
+
 function datesWithinSameMonth(d1, d2) {
     return d1.getFullYear() === d2.getFullYear() && d1.getMonth() === d2.getMonth();
 }
@@ -1,6 +1,6 @@
 import { setupPopovers } from './popover.js';
 import { setupMasonries } from './masonry.js';
-import { throttledDebounce, isElementVisible, openURLInNewTab } from './utils.js';
+import { throttledDebounce, isElementVisible, openURLInNewTab, configuredLocale } from './utils.js';
 
 async function fetchPageContent(pageData) {
     // TODO: handle non 200 status codes/time outs
@@ -593,8 +593,13 @@ function setupClocks() {
 
         updateCallbacks.push((now) => {
             setLocalTime(now);
-            localDateElement.textContent = now.getDate() + ' ' + monthNames[now.getMonth()];
-            localWeekdayElement.textContent = weekDayNames[now.getDay()];
+            if (configuredLocale) {
+                localDateElement.textContent = now.toLocaleDateString(configuredLocale, { day: 'numeric', month: 'long' });
+                localWeekdayElement.textContent = now.toLocaleDateString(configuredLocale, { weekday: 'long' });
+            } else {
+                localDateElement.textContent = now.getDate() + ' ' + monthNames[now.getMonth()];
+                localWeekdayElement.textContent = weekDayNames[now.getDay()];
+            }
             localYearElement.textContent = now.getFullYear();
         });
 
@@ -640,6 +645,54 @@ async function setupCalendars() {
         calendar.default(elems[i]);
 }
 
+function keyboardEventToShortcut(event) {
+    let key;
+
+    if (event.code.startsWith("Key")) {
+        key = event.code.slice(3).toLowerCase();
+    } else if (event.code.startsWith("Digit")) {
+        key = event.code.slice(5);
+    } else {
+        key = event.key.toLowerCase();
+    }
+
+    const modifiers = [];
+    if (event.ctrlKey) modifiers.push("ctrl");
+    if (event.altKey) modifiers.push("alt");
+    if (event.shiftKey) modifiers.push("shift");
+    if (event.metaKey) modifiers.push("meta");
+
+    if (modifiers.length == 0) {
+        return null;
+    }
+
+    return modifiers.join("+") + "+" + key;
+}
+
+function setupPageKeyboardShortcuts() {
+    const links = document.querySelectorAll(".nav-item[data-shortcut]");
+
+    if (links.length == 0) {
+        return;
+    }
+
+    const linksByShortcut = {};
+
+    for (let i = 0; i < links.length; i++) {
+        linksByShortcut[links[i].dataset.shortcut] = links[i];
+    }
+
+    document.addEventListener("keydown", (event) => {
+        if (['INPUT', 'TEXTAREA'].includes(document.activeElement.tagName)) return;
+
+        const shortcut = keyboardEventToShortcut(event);
+        if (shortcut === null || !(shortcut in linksByShortcut)) return;
+
+        event.preventDefault();
+        window.location.href = linksByShortcut[shortcut].href;
+    });
+}
+
 function setupTruncatedElementTitles() {
     const elements = document.querySelectorAll(".text-truncate, .single-line-titles .title, .text-truncate-2-lines, .text-truncate-3-lines");
 
@@ -653,7 +706,68 @@ function setupTruncatedElementTitles() {
     }
 }
 
+function setupBanner() {
+    const banner = document.querySelector(".banner[data-banner-id]");
+
+    if (banner === null) {
+        return;
+    }
+
+    banner.querySelector(".banner-dismiss").addEventListener("click", () => {
+        const path = pageData.baseURL === "" ? "/" : pageData.baseURL;
+        document.cookie = `glance_banner_dismissed=${banner.dataset.bannerId}; path=${path}; max-age=31536000; SameSite=Lax`;
+        banner.parentElement.remove();
+    });
+}
+
+function setupNavigationToggle() {
+    const toggle = document.querySelector(".nav-toggle");
+
+    if (toggle === null) {
+        return;
+    }
+
+    const root = document.documentElement;
+    toggle.setAttribute("aria-expanded", !root.classList.contains("nav-collapsed"));
+
+    toggle.addEventListener("click", () => {
+        const collapsed = root.classList.toggle("nav-collapsed");
+        toggle.setAttribute("aria-expanded", !collapsed);
+        localStorage.setItem("nav-collapsed", collapsed);
+    });
+}
+
+// Embedded pages are served from the same origin, which allows resizing
+// the iframe to the height of the page every time its content changes
+function setupPageEmbeds() {
+    const frames = document.querySelectorAll(".page-embed[data-fit-content]");
+
+    for (let i = 0; i < frames.length; i++) {
+        const frame = frames[i];
+
+        const observe = () => {
+            const body = frame.contentDocument?.body;
+            if (!body) return;
+
+            const observer = new ResizeObserver(() => {
+                frame.style.height = body.offsetHeight + "px";
+            });
+
+            observer.observe(body);
+        };
+
+        // the frame may have already loaded while the rest of the page was being set up
+        const doc = frame.contentDocument;
+        if (doc?.readyState === "complete" && doc.URL !== "about:blank") observe();
+        frame.addEventListener("load", observe);
+    }
+}
+
 async function setupPage() {
+    setupPageKeyboardShortcuts();
+    setupBanner();
+    setupNavigationToggle();
+
     const pageElement = document.getElementById("page");
     const pageContentElement = document.getElementById("page-content");
     const pageContent = await fetchPageContent(pageData);
@@ -672,6 +786,7 @@ async function setupPage() {
         setupMasonries();
         setupDynamicRelativeTime();
         setupLazyImages();
+        setupPageEmbeds();
     } finally {
         pageElement.classList.add("content-ready");
         pageElement.setAttribute("aria-busy", "false");
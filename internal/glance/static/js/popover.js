@@ -64,6 +64,8 @@ function showPopover() {
 
     const popoverType = activeTarget.dataset.popoverType;
 
+    containerElement.classList.toggle("popover-type-text", popoverType === "text");
+
     if (popoverType === "text") {
         const text = activeTarget.dataset.popoverText;
         if (text === undefined || text === "") return;
@@ -177,10 +179,15 @@ function handleHidePopoverOnEscape(event) {
 
 export function setupPopovers() {
     const targets = document.querySelectorAll("[data-popover-type]");
+    const tooltipsDisabled = document.documentElement.classList.contains("tooltip-style-none");
 
     for (let i = 0; i < targets.length; i++) {
         const target = targets[i];
 
+        if (tooltipsDisabled && target.dataset.popoverType === "text") {
+            continue;
+        }
+
         target.addEventListener("mouseenter", handleMouseEnter);
         target.addEventListener("mouseleave", handleMouseLeave);
     }
@@ -24,6 +24,9 @@ export function isElementVisible(element) {
     return !!(element.offsetWidth || element.offsetHeight || element.getClientRects().length);
 }
 
+// Only set when server.locale is, otherwise dates are shown in English
+export const configuredLocale = document.documentElement.dataset.locale;
+
 export function clamp(value, min, max) {
     return Math.min(Math.max(value, min), max);
 }
@@ -1,20 +1,16 @@
 package glance
 
 import (
-	"fmt"
 	"html/template"
 	"math"
 	"strconv"
-
-	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 )
 
-var intl = message.NewPrinter(language.English)
-
 var globalTemplateFunctions = template.FuncMap{
 	"formatApproxNumber": formatApproxNumber,
-	"formatNumber":       intl.Sprint,
+	"formatNumber": func(a ...any) string {
+		return intl().Sprint(a...)
+	},
 	"safeCSS": func(str string) template.CSS {
 		return template.CSS(str)
 	},
@@ -25,10 +21,10 @@ var globalTemplateFunctions = template.FuncMap{
 		return int(math.Abs(float64(i)))
 	},
 	"formatPrice": func(price float64) string {
-		return intl.Sprintf("%.2f", price)
+		return intl().Sprintf("%.2f", price)
 	},
 	"formatPriceWithPrecision": func(precision int, price float64) string {
-		return intl.Sprintf("%."+strconv.Itoa(precision)+"f", price)
+		return intl().Sprintf("%."+strconv.Itoa(precision)+"f", price)
 	},
 	"dynamicRelativeTimeAttrs": dynamicRelativeTimeAttrs,
 	"formatServerMegabytes": func(mb uint64) template.HTML {
@@ -40,14 +36,14 @@ var globalTemplateFunctions = template.FuncMap{
 			label = "MB"
 		} else if mb < 1_000_000 {
 			if mb < 10_000 {
-				value = fmt.Sprintf("%.1f", float64(mb)/1_000)
+				value = intl().Sprintf("%.1f", float64(mb)/1_000)
 			} else {
 				value = strconv.FormatUint(mb/1_000, 10)
 			}
 
 			label = "GB"
 		} else {
-			value = fmt.Sprintf("%.1f", float64(mb)/1_000_000)
+			value = intl().Sprintf("%.1f", float64(mb)/1_000_000)
 			label = "TB"
 		}
 
@@ -73,14 +69,14 @@ func formatApproxNumber(count int) string {
 	}
 
 	if count < 10_000 {
-		return strconv.FormatFloat(float64(count)/1_000, 'f', 1, 64) + "k"
+		return intl().Sprintf("%.1f", float64(count)/1_000) + "k"
 	}
 
 	if count < 1_000_000 {
 		return strconv.Itoa(count/1_000) + "k"
 	}
 
-	return strconv.FormatFloat(float64(count)/1_000_000, 'f', 1, 64) + "m"
+	return intl().Sprintf("%.1f", float64(count)/1_000_000) + "m"
 }
 
 func dynamicRelativeTimeAttrs(t interface{ Unix() int64 }) template.HTMLAttr {
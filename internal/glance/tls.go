@@ -0,0 +1,137 @@
+package glance
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Holds the certificate used for TLS and replaces it whenever the
+// certificate or key files change, without having to restart the server
+type tlsCertificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+}
+
+func newTLSCertificateReloader(certFile, keyFile string) (*tlsCertificateReloader, error) {
+	reloader := &tlsCertificateReloader{}
+
+	var err error
+	if reloader.certFile, err = filepath.Abs(certFile); err != nil {
+		return nil, fmt.Errorf("getting absolute path of cert file: %w", err)
+	}
+
+	if reloader.keyFile, err = filepath.Abs(keyFile); err != nil {
+		return nil, fmt.Errorf("getting absolute path of key file: %w", err)
+	}
+
+	if err := reloader.reload(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+func (r *tlsCertificateReloader) reload() error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.certificate = &certificate
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *tlsCertificateReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.certificate, nil
+}
+
+// Secrets mounted by Kubernetes have their files be symlinks into a ..data symlink which
+// points to a directory with the actual files. Updating the secret only swaps out ..data,
+// so the certificate and key files themselves never get any events.
+const kubernetesSecretDataDir = "..data"
+
+func (r *tlsCertificateReloader) isCertificateChange(event fsnotify.Event) bool {
+	if event.Name == r.certFile || event.Name == r.keyFile {
+		return event.Has(fsnotify.Write) || event.Has(fsnotify.Create)
+	}
+
+	if filepath.Base(event.Name) != kubernetesSecretDataDir || !event.Has(fsnotify.Create) {
+		return false
+	}
+
+	dir := filepath.Dir(event.Name)
+	return dir == filepath.Dir(r.certFile) || dir == filepath.Dir(r.keyFile)
+}
+
+// The directories are watched rather than the files themselves since
+// certificates usually get renewed by replacing the files or symlinks
+// to them, which would otherwise stop the files from being watched
+func (r *tlsCertificateReloader) watch() (func() error, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating watcher: %w", err)
+	}
+
+	for _, dir := range []string{filepath.Dir(r.certFile), filepath.Dir(r.keyFile)} {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("watching %s: %w", dir, err)
+		}
+	}
+
+	const debounceDuration = 500 * time.Millisecond
+	var debounceTimer *time.Timer
+
+	reload := func() {
+		if err := r.reload(); err != nil {
+			log.Printf("Failed to reload TLS certificate, continuing to use the previous one: %v", err)
+			return
+		}
+
+		log.Println("Reloaded TLS certificate")
+	}
+
+	go func() {
+		for {
+			select {
+			case event, isOpen := <-watcher.Events:
+				if !isOpen {
+					return
+				}
+
+				if !r.isCertificateChange(event) {
+					continue
+				}
+
+				if debounceTimer != nil {
+					debounceTimer.Reset(debounceDuration)
+				} else {
+					debounceTimer = time.AfterFunc(debounceDuration, reload)
+				}
+			case err, isOpen := <-watcher.Errors:
+				if !isOpen {
+					return
+				}
+
+				log.Printf("Error watching TLS certificate files: %v", err)
+			}
+		}
+	}()
+
+	return watcher.Close, nil
+}
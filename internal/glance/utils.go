@@ -2,11 +2,17 @@ package glance
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
@@ -100,6 +106,34 @@ func prefixStringLines(prefix string, s string) string {
 	return strings.Join(lines, "\n")
 }
 
+// The number of single character insertions, deletions and substitutions needed to turn a into b
+func levenshteinDistance(a, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := range runesA {
+		current[0] = i + 1
+
+		for j := range runesB {
+			substitution := previous[j]
+			if runesA[i] != runesB[j] {
+				substitution++
+			}
+
+			current[j+1] = min(previous[j+1]+1, current[j]+1, substitution)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(runesB)]
+}
+
 func limitStringLength(s string, max int) (string, bool) {
 	asRunes := []rune(s)
 
@@ -148,6 +182,177 @@ func fileServerWithCache(fs http.FileSystem, cacheDuration time.Duration) http.H
 	})
 }
 
+// Looks for files in each of the directories in order and opens the first one found
+type multiDirFileSystem []http.Dir
+
+func newMultiDirFileSystem(paths []string) multiDirFileSystem {
+	dirs := make(multiDirFileSystem, len(paths))
+	for i := range paths {
+		dirs[i] = http.Dir(paths[i])
+	}
+
+	return dirs
+}
+
+func (dirs multiDirFileSystem) Open(name string) (http.File, error) {
+	err := error(fs.ErrNotExist)
+
+	for _, dir := range dirs {
+		var file http.File
+		if file, err = dir.Open(name); err == nil {
+			return file, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
+
+// Checks that a path pointing to the assets-path through /assets/ refers to a file which
+// exists in one of the assets directories, paths that don't start with /assets/ are ignored
+func checkUserDefinedAssetExists(assetsPaths []string, path string) error {
+	asset, found := strings.CutPrefix(path, "/assets/")
+	if !found {
+		return nil
+	}
+
+	if len(assetsPaths) == 0 {
+		return fmt.Errorf("%s points to the assets-path but no assets-path is set", path)
+	}
+
+	asset, _, _ = strings.Cut(asset, "?")
+	asset, _, _ = strings.Cut(asset, "#")
+
+	file, err := newMultiDirFileSystem(assetsPaths).Open("/" + asset)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s does not exist in the assets-path", path)
+		}
+
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if info, err := file.Stat(); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory rather than a file", path)
+	}
+
+	return nil
+}
+
+// Sets the given headers on every response before the handler runs, so
+// handlers can still override them where needed, such as Cache-Control
+func withResponseHeaders(handler http.Handler, headers map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, value := range headers {
+			w.Header().Set(name, value)
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Sets an ETag computed from the content and responds with 304 Not Modified instead
+// of the content when the client's cached copy has the same ETag
+func writeWithETag(w http.ResponseWriter, r *http.Request, content []byte) {
+	hash := sha256.Sum256(content)
+	etag := `"` + hex.EncodeToString(hash[:12]) + `"`
+
+	w.Header().Set("ETag", etag)
+	// makes browsers check whether their cached copy is still
+	// the latest one every time rather than only after a while
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if etagListContains(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Write(content)
+}
+
+// Weak comparison as required for If-None-Match, see RFC 9110 section 13.1.2
+func etagListContains(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rejects any request which could change something, used for demo mode
+func withReadOnlyRequests(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			handler.ServeHTTP(w, r)
+		default:
+			http.Error(w, "This action is disabled in demo mode", http.StatusForbidden)
+		}
+	})
+}
+
+// Returns a map of each file's path within dir to the same path with a short
+// hash of the file's contents inserted before the extension (app.css -> app.1a2b3c4d.css)
+// Files from earlier directories take precedence over files with the
+// same path in later ones, matching how multiDirFileSystem serves them
+func computeAssetFingerprints(dirs []string) (map[string]string, error) {
+	fingerprints := make(map[string]string)
+
+	for _, dir := range dirs {
+		if err := computeAssetFingerprintsOfDir(dir, fingerprints); err != nil {
+			return nil, err
+		}
+	}
+
+	return fingerprints, nil
+}
+
+func computeAssetFingerprintsOfDir(dir string, fingerprints map[string]string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		relativePath = filepath.ToSlash(relativePath)
+		if _, exists := fingerprints[relativePath]; exists {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		hash := sha256.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			return err
+		}
+
+		ext := filepath.Ext(relativePath)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))[:8]
+		fingerprints[relativePath] = strings.TrimSuffix(relativePath, ext) + "." + fingerprint + ext
+
+		return nil
+	})
+}
+
 func executeTemplateToHTML(t *template.Template, data interface{}) (template.HTML, error) {
 	var b bytes.Buffer
 
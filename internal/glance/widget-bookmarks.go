@@ -66,11 +66,22 @@ func (widget *bookmarksWidget) initialize() error {
 		}
 	}
 
-	widget.cachedHTML = widget.renderTemplate(widget, bookmarksWidgetTemplate)
-
 	return nil
 }
 
+func (widget *bookmarksWidget) setProviders(providers *widgetProviders) {
+	widget.widgetBase.setProviders(providers)
+
+	for g := range widget.Groups {
+		for l := range widget.Groups[g].Links {
+			icon := &widget.Groups[g].Links[l].Icon
+			icon.URL = providers.userAssetResolver(icon.URL)
+		}
+	}
+
+	widget.cachedHTML = widget.renderTemplate(widget, bookmarksWidgetTemplate)
+}
+
 func (widget *bookmarksWidget) Render() template.HTML {
-	return widget.cachedHTML
+	return widget.renderCachedTemplate(widget.cachedHTML, widget, bookmarksWidgetTemplate)
 }
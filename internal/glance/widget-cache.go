@@ -0,0 +1,206 @@
+package glance
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/maintnotifications"
+)
+
+// A cache shared between multiple instances of Glance, used so that only one of
+// them has to fetch a response while the rest use the cached one. Widgets still
+// keep their data in memory, this only reduces the requests made to upstream APIs.
+type widgetCacheBackend interface {
+	// Returns false if there is no entry for the key
+	get(ctx context.Context, key string) ([]byte, bool, error)
+	set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	close()
+}
+
+// Responses bigger than this are fetched directly every time rather than being cached
+const maxSharedCacheEntrySize = 4 << 20
+
+// Returns nil when no backend is set, in which case nothing
+// but the widgets themselves caches their data
+func newWidgetCacheBackend(options *cacheConfigField) widgetCacheBackend {
+	switch options.Backend {
+	case "redis":
+		return newRedisCacheBackend(options.Redis.Address, options.Redis.Password, options.Redis.DB)
+	default:
+		return nil
+	}
+}
+
+type widgetCacheContextKey struct{}
+
+type widgetCacheContext struct {
+	id  string
+	ttl time.Duration
+}
+
+// The ID is the stable ID of the widget, which is the same across instances using the same config
+func contextWithWidgetCache(ctx context.Context, id string, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, widgetCacheContextKey{}, widgetCacheContext{id: id, ttl: ttl})
+}
+
+// Answers GET requests made with a widget's context from the shared cache when possible and
+// stores successful responses in it for as long as the widget caches its data. Requests are
+// made directly whenever the cache can't be reached, so an outage only costs extra requests.
+type sharedCacheTransport struct {
+	base      http.RoundTripper
+	backend   widgetCacheBackend
+	keyPrefix string
+	// whether the cache being unavailable has been logged, so that
+	// it's only logged once rather than for every single request
+	unavailable atomic.Bool
+}
+
+func (t *sharedCacheTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	widgetCache, ok := request.Context().Value(widgetCacheContextKey{}).(widgetCacheContext)
+	if !ok || widgetCache.ttl <= 0 || request.Method != http.MethodGet {
+		return t.base.RoundTrip(request)
+	}
+
+	ctx := request.Context()
+	key := t.keyPrefix + widgetCache.id + ":" + sharedCacheRequestHash(request)
+
+	cached, found, err := t.backend.get(ctx, key)
+	t.observeAvailability(ctx, err)
+
+	if found {
+		response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(cached)), request)
+		if err == nil {
+			return response, nil
+		}
+	}
+
+	response, err := t.base.RoundTrip(request)
+	if err != nil || response.StatusCode != http.StatusOK || response.ContentLength > maxSharedCacheEntrySize {
+		return response, err
+	}
+
+	// the length isn't always known up front, so at most one byte over the limit gets read,
+	// which is enough to know that it's too big, after which the response is passed on as is
+	body := response.Body
+	limited, err := io.ReadAll(io.LimitReader(body, maxSharedCacheEntrySize+1))
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+
+	if len(limited) > maxSharedCacheEntrySize {
+		response.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(limited), body), body}
+		return response, nil
+	}
+
+	body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(limited))
+
+	dumped, err := httputil.DumpResponse(response, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dumped) <= maxSharedCacheEntrySize {
+		t.observeAvailability(ctx, t.backend.set(ctx, key, dumped, widgetCache.ttl))
+	}
+
+	return response, nil
+}
+
+func (t *sharedCacheTransport) observeAvailability(ctx context.Context, err error) {
+	if err != nil {
+		if !t.unavailable.Swap(true) {
+			slog.WarnContext(ctx, "Shared cache is unavailable, fetching directly until it's available again", "error", err)
+		}
+	} else if t.unavailable.Swap(false) {
+		slog.InfoContext(ctx, "Shared cache is available again")
+	}
+}
+
+// Hashed so that credentials in the URL or headers don't end up in the keys
+func sharedCacheRequestHash(request *http.Request) string {
+	hash := sha256.New()
+	io.WriteString(hash, request.Method+" "+request.URL.String()+"\n")
+
+	names := make([]string, 0, len(request.Header))
+	for name := range request.Header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		for _, value := range request.Header[name] {
+			io.WriteString(hash, name+": "+value+"\n")
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+const (
+	redisDialTimeout    = 2 * time.Second
+	redisCommandTimeout = 2 * time.Second
+	redisMaxIdleConns   = 8
+)
+
+type redisCacheBackend struct {
+	client *redis.Client
+}
+
+func newRedisCacheBackend(address, password string, db int) *redisCacheBackend {
+	return &redisCacheBackend{
+		client: redis.NewClient(&redis.Options{
+			Addr:                  address,
+			Password:              password,
+			DB:                    db,
+			DialTimeout:           redisDialTimeout,
+			ReadTimeout:           redisCommandTimeout,
+			WriteTimeout:          redisCommandTimeout,
+			ContextTimeoutEnabled: true,
+			MaxIdleConns:          redisMaxIdleConns,
+			// only plain GET and SET get used, so there's no need for RESP3 or for the client
+			// to identify itself, which also keeps it working with older versions and forks
+			Protocol:                 2,
+			DisableIdentity:          true,
+			MaintNotificationsConfig: &maintnotifications.Config{Mode: maintnotifications.ModeDisabled},
+		}),
+	}
+}
+
+func (r *redisCacheBackend) get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (r *redisCacheBackend) set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	// expirations are in milliseconds and one of 0 would mean that the key never expires
+	return r.client.Set(ctx, key, value, max(ttl, time.Millisecond)).Err()
+}
+
+func (r *redisCacheBackend) close() {
+	r.client.Close()
+}
@@ -0,0 +1,414 @@
+package glance
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Speaks enough of the Redis protocol to answer the commands sent by the backend,
+// recording every command so that tests can check exactly what was sent
+type fakeRedisServer struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	commands [][]string
+	values   map[string]string
+	// when set, written as is instead of the usual reply
+	rawReply string
+}
+
+func newFakeRedisServer(t *testing.T) *fakeRedisServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+
+	server := &fakeRedisServer{listener: listener, values: make(map[string]string)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go server.serve(conn)
+		}
+	}()
+
+	return server
+}
+
+func (s *fakeRedisServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		args, err := readFakeRedisCommand(reader)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, args)
+		reply := s.rawReply
+		if reply == "" {
+			reply = s.reply(args)
+		}
+		s.mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedisServer) reply(args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		value, ok := s.values[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+
+		return "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+	case "SET":
+		s.values[args[1]] = args[2]
+		return "+OK\r\n"
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+// Leaves out the HELLO the client starts every connection with, which gets
+// rejected just like older versions of Redis do so that it falls back to RESP2
+func (s *fakeRedisServer) recordedCommands() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.DeleteFunc(slices.Clone(s.commands), func(args []string) bool {
+		return strings.EqualFold(args[0], "HELLO")
+	})
+}
+
+func readFakeRedisCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := strconv.Atoi(strings.TrimSuffix(line[1:], "\r\n"))
+	if err != nil || line[0] != '*' {
+		return nil, errors.New("expected an array")
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		length, err := strconv.Atoi(strings.TrimSuffix(line[1:], "\r\n"))
+		if err != nil || line[0] != '$' {
+			return nil, errors.New("expected a bulk string")
+		}
+
+		value := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, value); err != nil {
+			return nil, err
+		}
+
+		args[i] = string(value[:length])
+	}
+
+	return args, nil
+}
+
+func TestRedisCacheBackendSetsWithTTLAndGets(t *testing.T) {
+	server := newFakeRedisServer(t)
+	backend := newRedisCacheBackend(server.listener.Addr().String(), "", 0)
+	defer backend.close()
+
+	ctx := context.Background()
+	value := "line one\r\nline two with $ and * in it"
+
+	if err := backend.set(ctx, "glance:key", []byte(value), 1500*time.Millisecond); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	got, found, err := backend.get(ctx, "glance:key")
+	if err != nil || !found || string(got) != value {
+		t.Fatalf("get = %q, %v, %v, want %q, true, nil", got, found, err, value)
+	}
+
+	_, found, err = backend.get(ctx, "glance:missing")
+	if err != nil || found {
+		t.Fatalf("get of a missing key = found %v, error %v, want not found without an error", found, err)
+	}
+
+	want := [][]string{
+		{"set", "glance:key", value, "px", "1500"},
+		{"get", "glance:key"},
+		{"get", "glance:missing"},
+	}
+
+	if got := server.recordedCommands(); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestRedisCacheBackendAuthenticatesAndSelectsDatabase(t *testing.T) {
+	server := newFakeRedisServer(t)
+	backend := newRedisCacheBackend(server.listener.Addr().String(), "secret", 3)
+	defer backend.close()
+
+	if _, _, err := backend.get(context.Background(), "key"); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	want := [][]string{{"auth", "secret"}, {"select", "3"}, {"get", "key"}}
+	if got := server.recordedCommands(); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestRedisCacheBackendNeverSetsKeysWithoutExpiration(t *testing.T) {
+	server := newFakeRedisServer(t)
+	backend := newRedisCacheBackend(server.listener.Addr().String(), "", 0)
+	defer backend.close()
+
+	if err := backend.set(context.Background(), "key", []byte("value"), time.Microsecond); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	want := [][]string{{"set", "key", "value", "px", "1"}}
+	if got := server.recordedCommands(); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestRedisCacheBackendReturnsErrorReplies(t *testing.T) {
+	server := newFakeRedisServer(t)
+	server.rawReply = "-WRONGTYPE not a string\r\n"
+	backend := newRedisCacheBackend(server.listener.Addr().String(), "", 0)
+	defer backend.close()
+
+	_, found, err := backend.get(context.Background(), "key")
+	if err == nil || found || !strings.Contains(err.Error(), "WRONGTYPE not a string") {
+		t.Fatalf("get = found %v, error %v, want the error reply", found, err)
+	}
+}
+
+type fakeWidgetCacheBackend struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+	ttls    map[string]time.Duration
+}
+
+func newFakeWidgetCacheBackend() *fakeWidgetCacheBackend {
+	return &fakeWidgetCacheBackend{entries: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (b *fakeWidgetCacheBackend) get(_ context.Context, key string) ([]byte, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	value, ok := b.entries[key]
+	return value, ok, nil
+}
+
+func (b *fakeWidgetCacheBackend) set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries[key] = value
+	b.ttls[key] = ttl
+	return nil
+}
+
+func (b *fakeWidgetCacheBackend) close() {}
+
+type countingRoundTripper struct {
+	requests int
+	status   int
+	// when set, used as the body instead of one that's different for every request
+	body string
+	// whether the length of the body is left unknown, like with chunked responses
+	unknownLength bool
+	// how many bytes of the bodies have been read so far
+	read int
+}
+
+type countingReader struct {
+	reader io.Reader
+	read   *int
+}
+
+func (r countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	*r.read += n
+	return n, err
+}
+
+func (c *countingRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	c.requests++
+	body := "response " + strconv.Itoa(c.requests)
+	if c.body != "" {
+		body = c.body
+	}
+
+	contentLength := int64(len(body))
+	if c.unknownLength {
+		contentLength = -1
+	}
+
+	return &http.Response{
+		StatusCode:    c.status,
+		Status:        strconv.Itoa(c.status) + " " + http.StatusText(c.status),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/plain"}},
+		Body:          io.NopCloser(countingReader{reader: strings.NewReader(body), read: &c.read}),
+		ContentLength: contentLength,
+		Request:       request,
+	}, nil
+}
+
+func TestSharedCacheTransportCachesForTheWidgetTTL(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		status       int
+		ttl          time.Duration
+		withWidget   bool
+		wantRequests int
+		wantTTL      time.Duration
+	}{
+		{name: "cached for the widget's ttl", method: http.MethodGet, status: http.StatusOK, ttl: time.Minute, withWidget: true, wantRequests: 1, wantTTL: time.Minute},
+		{name: "not cached without a ttl", method: http.MethodGet, status: http.StatusOK, ttl: 0, withWidget: true, wantRequests: 2},
+		{name: "not cached without a widget", method: http.MethodGet, status: http.StatusOK, wantRequests: 2},
+		{name: "only GET requests get cached", method: http.MethodPost, status: http.StatusOK, ttl: time.Minute, withWidget: true, wantRequests: 2},
+		{name: "failed responses don't get cached", method: http.MethodGet, status: http.StatusBadGateway, ttl: time.Minute, withWidget: true, wantRequests: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := newFakeWidgetCacheBackend()
+			base := &countingRoundTripper{status: test.status}
+			transport := &sharedCacheTransport{base: base, backend: backend, keyPrefix: "glance:"}
+
+			ctx := context.Background()
+			if test.withWidget {
+				ctx = contextWithWidgetCache(ctx, "rss-1-1", test.ttl)
+			}
+
+			var bodies []string
+			for range 2 {
+				request, _ := http.NewRequestWithContext(ctx, test.method, "https://example.com/feed", nil)
+				response, err := transport.RoundTrip(request)
+				if err != nil {
+					t.Fatalf("round trip: %v", err)
+				}
+
+				body, _ := io.ReadAll(response.Body)
+				response.Body.Close()
+				bodies = append(bodies, string(body))
+			}
+
+			if base.requests != test.wantRequests {
+				t.Fatalf("upstream got %d requests, want %d", base.requests, test.wantRequests)
+			}
+
+			if test.wantRequests == 1 && bodies[0] != bodies[1] {
+				t.Fatalf("cached body = %q, want %q", bodies[1], bodies[0])
+			}
+
+			if test.wantTTL == 0 {
+				if len(backend.ttls) != 0 {
+					t.Fatalf("backend got %d entries, want none", len(backend.ttls))
+				}
+				return
+			}
+
+			for key, ttl := range backend.ttls {
+				if !strings.HasPrefix(key, "glance:rss-1-1:") {
+					t.Errorf("key %s doesn't start with the prefix and widget ID", key)
+				}
+
+				if ttl != test.wantTTL {
+					t.Errorf("ttl = %s, want %s", ttl, test.wantTTL)
+				}
+			}
+		})
+	}
+}
+
+func TestSharedCacheTransportSkipsOversizedResponsesOfUnknownLength(t *testing.T) {
+	backend := newFakeWidgetCacheBackend()
+	body := strings.Repeat("a", 2*maxSharedCacheEntrySize)
+	base := &countingRoundTripper{status: http.StatusOK, body: body, unknownLength: true}
+	transport := &sharedCacheTransport{base: base, backend: backend, keyPrefix: "glance:"}
+
+	ctx := contextWithWidgetCache(context.Background(), "custom-api-1-1", time.Minute)
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/large", nil)
+
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		t.Fatalf("round trip: %v", err)
+	}
+
+	if base.read > maxSharedCacheEntrySize+1 {
+		t.Fatalf("read %d bytes of the body before returning the response, want at most %d", base.read, maxSharedCacheEntrySize+1)
+	}
+
+	got, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+
+	if string(got) != body {
+		t.Fatalf("body has %d bytes, want all %d of them", len(got), len(body))
+	}
+
+	if len(backend.entries) != 0 {
+		t.Fatalf("backend got %d entries, want none", len(backend.entries))
+	}
+}
+
+func TestSharedCacheRequestHashIgnoresHeaderOrder(t *testing.T) {
+	first, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+	first.Header.Set("Authorization", "Bearer token")
+	first.Header.Set("Accept", "application/json")
+
+	second, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+	second.Header.Set("Accept", "application/json")
+	second.Header.Set("Authorization", "Bearer token")
+
+	if sharedCacheRequestHash(first) != sharedCacheRequestHash(second) {
+		t.Fatal("requests with the same headers in a different order have different hashes")
+	}
+
+	second.Header.Set("Authorization", "Bearer other")
+	if sharedCacheRequestHash(first) == sharedCacheRequestHash(second) {
+		t.Fatal("requests with different headers have the same hash")
+	}
+
+	if strings.Contains(sharedCacheRequestHash(first), "token") {
+		t.Fatal("hash contains the value of a header")
+	}
+}
@@ -41,5 +41,5 @@ func (widget *calendarWidget) initialize() error {
 }
 
 func (widget *calendarWidget) Render() template.HTML {
-	return widget.cachedHTML
+	return widget.renderCachedTemplate(widget.cachedHTML, widget, calendarWidgetTemplate)
 }
@@ -43,7 +43,7 @@ func (widget *changeDetectionWidget) initialize() error {
 
 func (widget *changeDetectionWidget) update(ctx context.Context) {
 	if len(widget.WatchUUIDs) == 0 {
-		uuids, err := fetchWatchUUIDsFromChangeDetection(widget.InstanceURL, string(widget.Token))
+		uuids, err := fetchWatchUUIDsFromChangeDetection(ctx, widget.InstanceURL, string(widget.Token))
 
 		if !widget.canContinueUpdateAfterHandlingErr(err) {
 			return
@@ -52,7 +52,7 @@ func (widget *changeDetectionWidget) update(ctx context.Context) {
 		widget.WatchUUIDs = uuids
 	}
 
-	watches, err := fetchWatchesFromChangeDetection(widget.InstanceURL, widget.WatchUUIDs, string(widget.Token))
+	watches, err := fetchWatchesFromChangeDetection(ctx, widget.InstanceURL, widget.WatchUUIDs, string(widget.Token))
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
@@ -95,14 +95,14 @@ type changeDetectionResponseJson struct {
 	PreviousHash string `json:"previous_md5"`
 }
 
-func fetchWatchUUIDsFromChangeDetection(instanceURL string, token string) ([]string, error) {
-	request, _ := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/watch", instanceURL), nil)
+func fetchWatchUUIDsFromChangeDetection(ctx context.Context, instanceURL string, token string) ([]string, error) {
+	request, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/v1/watch", instanceURL), nil)
 
 	if token != "" {
 		request.Header.Add("x-api-key", token)
 	}
 
-	uuidsMap, err := decodeJsonFromRequest[map[string]struct{}](defaultHTTPClient, request)
+	uuidsMap, err := decodeJsonFromRequest[map[string]struct{}](widgetHTTPClient(ctx, false), request)
 	if err != nil {
 		return nil, fmt.Errorf("could not fetch list of watch UUIDs: %v", err)
 	}
@@ -116,7 +116,7 @@ func fetchWatchUUIDsFromChangeDetection(instanceURL string, token string) ([]str
 	return uuids, nil
 }
 
-func fetchWatchesFromChangeDetection(instanceURL string, requestedWatchIDs []string, token string) (changeDetectionWatchList, error) {
+func fetchWatchesFromChangeDetection(ctx context.Context, instanceURL string, requestedWatchIDs []string, token string) (changeDetectionWatchList, error) {
 	watches := make(changeDetectionWatchList, 0, len(requestedWatchIDs))
 
 	if len(requestedWatchIDs) == 0 {
@@ -126,7 +126,7 @@ func fetchWatchesFromChangeDetection(instanceURL string, requestedWatchIDs []str
 	requests := make([]*http.Request, len(requestedWatchIDs))
 
 	for i, repository := range requestedWatchIDs {
-		request, _ := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/watch/%s", instanceURL, repository), nil)
+		request, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/v1/watch/%s", instanceURL, repository), nil)
 
 		if token != "" {
 			request.Header.Add("x-api-key", token)
@@ -135,7 +135,7 @@ func fetchWatchesFromChangeDetection(instanceURL string, requestedWatchIDs []str
 		requests[i] = request
 	}
 
-	task := decodeJsonFromRequestTask[changeDetectionResponseJson](defaultHTTPClient)
+	task := decodeJsonFromRequestTask[changeDetectionResponseJson](widgetHTTPClient(ctx, false))
 	job := newJob(task, requests).withWorkers(15)
 	responses, errs, err := workerPoolDo(job)
 	if err != nil {
@@ -147,7 +147,7 @@ func fetchWatchesFromChangeDetection(instanceURL string, requestedWatchIDs []str
 	for i := range responses {
 		if errs[i] != nil {
 			failed++
-			slog.Error("Failed to fetch or parse change detection watch", "url", requests[i].URL, "error", errs[i])
+			slog.ErrorContext(ctx, "Failed to fetch or parse change detection watch", "url", requests[i].URL, "error", errs[i])
 			continue
 		}
 
@@ -44,5 +44,5 @@ func (widget *clockWidget) initialize() error {
 }
 
 func (widget *clockWidget) Render() template.HTML {
-	return widget.cachedHTML
+	return widget.renderCachedTemplate(widget.cachedHTML, widget, clockWidgetTemplate)
 }
@@ -10,6 +10,21 @@ type containerWidgetBase struct {
 	Widgets widgets `yaml:"widgets"`
 }
 
+func (widget *containerWidgetBase) containedWidgets() widgets {
+	return widget.Widgets
+}
+
+func (widget *containerWidgetBase) VisibleWidgets() widgets {
+	visible := make(widgets, 0, len(widget.Widgets))
+	for i := range widget.Widgets {
+		if widget.Widgets[i].IsVisible() {
+			visible = append(visible, widget.Widgets[i])
+		}
+	}
+
+	return visible
+}
+
 func (widget *containerWidgetBase) _initializeWidgets() error {
 	for i := range widget.Widgets {
 		if err := widget.Widgets[i].initialize(); err != nil {
@@ -34,7 +49,7 @@ func (widget *containerWidgetBase) _update(ctx context.Context) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			widget.update(ctx)
+			updateWidgetWithTimeout(ctx, widget)
 		}()
 	}
 
@@ -33,8 +33,12 @@ type CustomAPIRequest struct {
 	BodyType           string               `yaml:"body-type"`
 	Body               any                  `yaml:"body"`
 	SkipJSONValidation bool                 `yaml:"skip-json-validation"`
-	bodyReader         io.ReadSeeker        `yaml:"-"`
-	httpRequest        *http.Request        `yaml:"-"`
+	Transform          *struct {
+		JQ string `yaml:"jq"`
+	} `yaml:"transform"`
+	bodyReader     io.ReadSeeker `yaml:"-"`
+	httpRequest    *http.Request `yaml:"-"`
+	transformQuery *jqQuery      `yaml:"-"`
 }
 
 type customAPIWidget struct {
@@ -74,8 +78,30 @@ func (widget *customAPIWidget) initialize() error {
 	return nil
 }
 
+// The rest of the request properties, such as parameters and transform, can still be set on the widget
+func (widget *customAPIWidget) useDataSource(source *widgetDataSource) error {
+	if widget.CustomAPIRequest == nil {
+		widget.CustomAPIRequest = &CustomAPIRequest{}
+	} else if widget.CustomAPIRequest.URL != "" {
+		return errors.New("cannot be used together with url")
+	}
+
+	widget.CustomAPIRequest.URL = source.URL
+	widget.CustomAPIRequest.Method = source.Method
+	widget.CustomAPIRequest.Headers = source.Headers
+	widget.CustomAPIRequest.SkipJSONValidation = source.Format == "text"
+
+	return nil
+}
+
+// The headers are only meant for the primary request, which sets them by itself,
+// rather than for the subrequests which may be sent to an entirely different API
+func (widget *customAPIWidget) getRequestHeaders() map[string]string {
+	return nil
+}
+
 func (widget *customAPIWidget) update(ctx context.Context) {
-	compiledHTML, err := fetchAndParseCustomAPI(widget.CustomAPIRequest, widget.Subrequests, widget.compiledTemplate)
+	compiledHTML, err := fetchAndParseCustomAPI(ctx, widget.CustomAPIRequest, widget.Subrequests, widget.compiledTemplate)
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
 	}
@@ -126,6 +152,23 @@ func (req *CustomAPIRequest) initialize() error {
 		req.Method = http.MethodGet
 	}
 
+	if req.Transform != nil {
+		if req.Transform.JQ == "" {
+			return errors.New("transform: jq is required")
+		}
+
+		if req.SkipJSONValidation {
+			return errors.New("transform cannot be used when the response isn't JSON")
+		}
+
+		query, err := parseJQQuery(req.Transform.JQ)
+		if err != nil {
+			return fmt.Errorf("transform: parsing jq: %v", err)
+		}
+
+		req.transformQuery = query
+	}
+
 	httpReq, err := http.NewRequest(strings.ToUpper(req.Method), req.URL, req.bodyReader)
 	if err != nil {
 		return err
@@ -150,6 +193,7 @@ func (req *CustomAPIRequest) initialize() error {
 
 type customAPIResponseData struct {
 	JSON     decoratedGJSONResult
+	Text     string
 	Response *http.Response
 }
 
@@ -188,7 +232,7 @@ func fetchCustomAPIRequest(ctx context.Context, req *CustomAPIRequest) (*customA
 		req.bodyReader.Seek(0, io.SeekStart)
 	}
 
-	client := ternary(req.AllowInsecure, defaultInsecureHTTPClient, defaultHTTPClient)
+	client := widgetHTTPClient(ctx, req.AllowInsecure)
 	resp, err := client.Do(req.httpRequest.WithContext(ctx))
 	if err != nil {
 		return nil, err
@@ -208,12 +252,22 @@ func fetchCustomAPIRequest(ctx context.Context, req *CustomAPIRequest) (*customA
 			truncatedBody += "... <truncated>"
 		}
 
-		slog.Error("Invalid response JSON in custom API widget", "url", req.httpRequest.URL.String(), "body", truncatedBody)
+		slog.ErrorContext(ctx, "Invalid response JSON in custom API widget", "url", req.httpRequest.URL.String(), "body", truncatedBody)
 		return nil, errors.New("invalid response JSON")
 	}
 
+	if req.transformQuery != nil && body != "" {
+		transformed, err := req.transformQuery.apply(ctx, []byte(body))
+		if err != nil {
+			return nil, fmt.Errorf("transforming response: %v", err)
+		}
+
+		body = string(transformed)
+	}
+
 	data := &customAPIResponseData{
 		JSON:     decoratedGJSONResult{gjson.Parse(body)},
+		Text:     body,
 		Response: resp,
 	}
 
@@ -221,6 +275,7 @@ func fetchCustomAPIRequest(ctx context.Context, req *CustomAPIRequest) (*customA
 }
 
 func fetchAndParseCustomAPI(
+	ctx context.Context,
 	primaryReq *CustomAPIRequest,
 	subReqs map[string]*CustomAPIRequest,
 	tmpl *template.Template,
@@ -231,12 +286,12 @@ func fetchAndParseCustomAPI(
 
 	if len(subReqs) == 0 {
 		// If there are no subrequests, we can fetch the primary request in a much simpler way
-		primaryData, err = fetchCustomAPIRequest(context.Background(), primaryReq)
+		primaryData, err = fetchCustomAPIRequest(ctx, primaryReq)
 	} else {
 		// If there are subrequests, we need to fetch them concurrently
 		// and cancel all requests if any of them fail. There's probably
 		// a more elegant way to do this, but this works for now.
-		ctx, cancel := context.WithCancel(context.Background())
+		ctx, cancel := context.WithCancel(ctx)
 		defer cancel()
 
 		var wg sync.WaitGroup
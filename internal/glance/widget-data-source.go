@@ -0,0 +1,59 @@
+package glance
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A generic description of where a widget fetches its data from, shared by every widget
+// so that ones which render a template of the user's can get their data the same way
+type widgetDataSource struct {
+	Type    string            `yaml:"type"`
+	URL     string            `yaml:"url"`
+	Method  string            `yaml:"method"`
+	Headers map[string]string `yaml:"headers"`
+	Format  string            `yaml:"format"`
+}
+
+// Implemented by widgets which render the data from data-source with a template, the
+// source has already been validated and had its defaults set by the time it's given
+type dataSourceWidget interface {
+	useDataSource(source *widgetDataSource) error
+}
+
+func (source *widgetDataSource) initialize() error {
+	if source.Type == "" {
+		source.Type = "http"
+	}
+
+	if source.Type != "http" {
+		return fmt.Errorf("unsupported type %s, only http is supported", source.Type)
+	}
+
+	if source.URL == "" {
+		return errors.New("url is required")
+	}
+
+	if source.Format == "" {
+		source.Format = "json"
+	}
+
+	if source.Format != "json" && source.Format != "text" {
+		return fmt.Errorf("invalid format %s, must be either json or text", source.Format)
+	}
+
+	return nil
+}
+
+func applyWidgetDataSource(widget widget, source *widgetDataSource) error {
+	consumer, ok := widget.(dataSourceWidget)
+	if !ok {
+		return fmt.Errorf("%s widgets cannot use data-source since they don't have a template to render its data with", widget.GetType())
+	}
+
+	if err := source.initialize(); err != nil {
+		return err
+	}
+
+	return consumer.useDataSource(source)
+}
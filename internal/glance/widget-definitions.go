@@ -0,0 +1,310 @@
+package glance
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type widgetDefinitions map[string]yaml.Node
+
+// Placeholder for a `use: <id>` entry, replaced with the definition it points to
+// before widgets get initialized
+type widgetReference struct {
+	widgetBase `yaml:",inline"`
+	Use        string `yaml:"use"`
+}
+
+func (widget *widgetReference) initialize() error {
+	return fmt.Errorf("unresolved reference to widget definition %s", widget.Use)
+}
+
+func (widget *widgetReference) Render() template.HTML {
+	return ""
+}
+
+// A single widget instance that may be placed in multiple columns and pages.
+// Pages get updated independently of each other so all access to the
+// underlying widget has to be synchronized.
+type sharedWidget struct {
+	widget
+	mu          sync.Mutex
+	initialized bool
+	initErr     error
+	// set while one of the pages is going through the attempts of an update,
+	// during which the other pages show what the widget last rendered
+	updating atomic.Bool
+}
+
+// One of the places a shared widget is used in. The ID of the container it's in and a suffix
+// such as -2 for the places after the first on a page get added to the IDs of the widget and
+// the widgets within it when rendered, that way the IDs of elements are unique within the page
+type sharedWidgetPlacement struct {
+	*sharedWidget
+	idPrefix   string
+	idSuffix   string
+	lastRender atomic.Pointer[template.HTML]
+}
+
+func (widget *sharedWidget) initialize() error {
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	if !widget.initialized {
+		widget.initialized = true
+		widget.initErr = widget.widget.initialize()
+	}
+
+	return widget.initErr
+}
+
+func (widget *sharedWidget) requiresUpdate(now *time.Time) bool {
+	if widget.updating.Load() {
+		return false
+	}
+
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	return widget.widget.requiresUpdate(now)
+}
+
+func (widget *sharedWidget) isAwaitingFirstUpdate() bool {
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	return widget.widget.isAwaitingFirstUpdate()
+}
+
+func (widget *sharedWidget) update(ctx context.Context) {
+	widget.mu.Lock()
+
+	// another page may have updated the widget while we were waiting for the lock,
+	// or may still be retrying it in which case its result is what gets shown
+	now := time.Now()
+	if widget.updating.Load() || !widget.widget.requiresUpdate(&now) {
+		widget.mu.Unlock()
+		return
+	}
+
+	if widget.widget.isMocked() {
+		updateWidgetWithMockData(widget.widget)
+		widget.mu.Unlock()
+		return
+	}
+
+	widget.updating.Store(true)
+	widget.mu.Unlock()
+	defer widget.updating.Store(false)
+
+	// the lock is only held during each attempt rather than across the backoff in between
+	// them, so that other pages the widget is on don't have to wait for the retries to render
+	retries, backoff := widget.widget.getRetryOptions()
+	retryTransientFetchErrors(ctx, retries, backoff, func() error {
+		widget.mu.Lock()
+		defer widget.mu.Unlock()
+
+		widget.widget.update(ctx)
+		return widget.widget.getError()
+	})
+}
+
+// Containers set this while being initialized, which can happen at the same
+// time as the widget itself gets initialized from elsewhere in the config
+func (widget *sharedWidget) setHideHeader(hide bool) {
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	widget.widget.setHideHeader(hide)
+}
+
+func (widget *sharedWidget) getError() error {
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	return widget.widget.getError()
+}
+
+func (placement *sharedWidgetPlacement) getStableID() string {
+	return placement.idPrefix + placement.sharedWidget.getStableID() + placement.idSuffix
+}
+
+func (placement *sharedWidgetPlacement) Render() template.HTML {
+	// shows what was last rendered while another page is fetching the widget's data rather than
+	// waiting for it, though before the first render there's nothing to show so that has to wait
+	if placement.updating.Load() {
+		if html := placement.lastRender.Load(); html != nil {
+			return *html
+		}
+	}
+
+	placement.mu.Lock()
+	defer placement.mu.Unlock()
+
+	html := placement.widget.Render()
+
+	// rendered widgets are often cached, so the IDs get replaced in the HTML rather than
+	// changing the IDs of the widgets, which would also need the shared widget's lock
+	if placement.idPrefix != "" || placement.idSuffix != "" {
+		var replacements []string
+
+		forEachWidgetStableID(widgets{placement.widget}, "", "", func(id string) error {
+			placed := placement.idPrefix + id + placement.idSuffix
+			replacements = append(replacements,
+				`id="widget-`+id+`"`, `id="widget-`+placed+`"`,
+				`data-widget-id="`+id+`"`, `data-widget-id="`+placed+`"`,
+			)
+			return nil
+		})
+
+		html = template.HTML(strings.NewReplacer(replacements...).Replace(string(html)))
+	}
+
+	placement.lastRender.Store(&html)
+	return html
+}
+
+type widgetDefinitionsResolver struct {
+	definitions widgetDefinitions
+	resolved    map[string]*sharedWidget
+	// the definitions currently being resolved, in the order they reference each other
+	resolving []string
+}
+
+func resolveWidgetDefinitions(config *config) error {
+	resolver := &widgetDefinitionsResolver{
+		definitions: config.Definitions,
+		resolved:    make(map[string]*sharedWidget),
+	}
+
+	ids := make([]string, 0, len(config.Definitions))
+	for id := range config.Definitions {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	// resolve all definitions, even unused ones, so that errors in them don't go unnoticed
+	for _, id := range ids {
+		if _, err := resolver.resolve(id); err != nil {
+			return err
+		}
+	}
+
+	for p := range config.Pages {
+		for c := range config.Pages[p].Columns {
+			if err := resolver.resolveWidgets(config.Pages[p].Columns[c].Widgets); err != nil {
+				return fmt.Errorf("page %d, column %d: %w", p+1, c+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func findUnknownWidget(w widget) *unknownWidget {
+	if unknown, ok := w.(*unknownWidget); ok {
+		return unknown
+	}
+
+	if container, ok := w.(widgetContainer); ok {
+		for _, contained := range container.containedWidgets() {
+			if unknown := findUnknownWidget(contained); unknown != nil {
+				return unknown
+			}
+		}
+	}
+
+	return nil
+}
+
+type widgetContainer interface {
+	containedWidgets() widgets
+}
+
+func (r *widgetDefinitionsResolver) resolveWidgets(list widgets) error {
+	for i := range list {
+		if reference, ok := list[i].(*widgetReference); ok {
+			shared, err := r.resolve(reference.Use)
+			if err != nil {
+				return err
+			}
+
+			list[i] = &sharedWidgetPlacement{sharedWidget: shared}
+			continue
+		}
+
+		if container, ok := list[i].(widgetContainer); ok {
+			if err := r.resolveWidgets(container.containedWidgets()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *widgetDefinitionsResolver) resolve(id string) (*sharedWidget, error) {
+	if shared, ok := r.resolved[id]; ok {
+		return shared, nil
+	}
+
+	if start := slices.Index(r.resolving, id); start >= 0 {
+		if start == len(r.resolving)-1 {
+			return nil, fmt.Errorf("widget definition %s references itself", id)
+		}
+
+		loop := append(slices.Clone(r.resolving[start:]), id)
+		return nil, fmt.Errorf("widget definitions reference each other in a loop: %s", strings.Join(loop, " -> "))
+	}
+
+	node, ok := r.definitions[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown widget definition: %s", id)
+	}
+
+	r.resolving = append(r.resolving, id)
+	defer func() { r.resolving = r.resolving[:len(r.resolving)-1] }()
+
+	widget, err := newWidgetFromYAMLNode(&node)
+	if err != nil {
+		return nil, fmt.Errorf("widget definition %s: %w", id, err)
+	}
+
+	// definitions that aren't used anywhere never get initialized, so this is checked right away
+	if unknown := findUnknownWidget(widget); unknown != nil {
+		return nil, fmt.Errorf("widget definition %s: %w", id, formatWidgetInitError(unknown.initialize(), unknown))
+	}
+
+	// a definition which only points to another one is the same widget as that one
+	if reference, ok := widget.(*widgetReference); ok {
+		shared, err := r.resolve(reference.Use)
+		if err != nil {
+			return nil, err
+		}
+
+		r.resolved[id] = shared
+		return shared, nil
+	}
+
+	if container, ok := widget.(widgetContainer); ok {
+		if err := r.resolveWidgets(container.containedWidgets()); err != nil {
+			return nil, fmt.Errorf("widget definition %s: %w", id, err)
+		}
+	}
+
+	// the same instance may appear in multiple places, so its ID can't be based on its position
+	if widget.getStableID() == "" {
+		widget.setStableID(id)
+	}
+
+	shared := &sharedWidget{widget: widget}
+	r.resolved[id] = shared
+	return shared, nil
+}
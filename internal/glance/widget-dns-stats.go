@@ -82,12 +82,13 @@ func (widget *dnsStatsWidget) update(ctx context.Context) {
 
 	switch widget.Service {
 	case dnsServiceAdguard:
-		stats, err = fetchAdguardStats(widget.URL, widget.AllowInsecure, widget.Username, widget.Password, widget.HideGraph)
+		stats, err = fetchAdguardStats(ctx, widget.URL, widget.AllowInsecure, widget.Username, widget.Password, widget.HideGraph)
 	case dnsServicePihole:
-		stats, err = fetchPihole5Stats(widget.URL, widget.AllowInsecure, widget.Token, widget.HideGraph)
+		stats, err = fetchPihole5Stats(ctx, widget.URL, widget.AllowInsecure, widget.Token, widget.HideGraph)
 	case dnsServicePiholeV6:
 		var newSessionID string
 		stats, newSessionID, err = fetchPiholeStats(
+			ctx,
 			widget.URL,
 			widget.AllowInsecure,
 			widget.Password,
@@ -148,17 +149,17 @@ type adguardStatsResponse struct {
 	TopBlockedDomains []map[string]int `json:"top_blocked_domains"`
 }
 
-func fetchAdguardStats(instanceURL string, allowInsecure bool, username, password string, noGraph bool) (*dnsStats, error) {
+func fetchAdguardStats(ctx context.Context, instanceURL string, allowInsecure bool, username, password string, noGraph bool) (*dnsStats, error) {
 	requestURL := strings.TrimRight(instanceURL, "/") + "/control/stats"
 
-	request, err := http.NewRequest("GET", requestURL, nil)
+	request, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	request.SetBasicAuth(username, password)
 
-	var client = ternary(allowInsecure, defaultInsecureHTTPClient, defaultHTTPClient)
+	var client = widgetHTTPClient(ctx, allowInsecure)
 	responseJson, err := decodeJsonFromRequest[adguardStatsResponse](client, request)
 	if err != nil {
 		return nil, err
@@ -301,7 +302,7 @@ func (p *pihole5TopBlockedDomains) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func fetchPihole5Stats(instanceURL string, allowInsecure bool, token string, noGraph bool) (*dnsStats, error) {
+func fetchPihole5Stats(ctx context.Context, instanceURL string, allowInsecure bool, token string, noGraph bool) (*dnsStats, error) {
 	if token == "" {
 		return nil, errors.New("missing API token")
 	}
@@ -309,12 +310,12 @@ func fetchPihole5Stats(instanceURL string, allowInsecure bool, token string, noG
 	requestURL := strings.TrimRight(instanceURL, "/") +
 		"/admin/api.php?summaryRaw&topItems&overTimeData10mins&auth=" + token
 
-	request, err := http.NewRequest("GET", requestURL, nil)
+	request, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var client = ternary(allowInsecure, defaultInsecureHTTPClient, defaultHTTPClient)
+	var client = widgetHTTPClient(ctx, allowInsecure)
 	responseJson, err := decodeJsonFromRequest[pihole5StatsResponse](client, request)
 	if err != nil {
 		return nil, err
@@ -350,7 +351,7 @@ func fetchPihole5Stats(instanceURL string, allowInsecure bool, token string, noG
 
 	// Pihole _should_ return data for the last 24 hours in a 10 minute interval, 6*24 = 144
 	if len(responseJson.QueriesSeries) != 144 || len(responseJson.BlockedSeries) != 144 {
-		slog.Warn(
+		slog.WarnContext(ctx,
 			"DNS stats for pihole: did not get expected 144 data points",
 			"len(queries)", len(responseJson.QueriesSeries),
 			"len(blocked)", len(responseJson.BlockedSeries),
@@ -400,6 +401,7 @@ func fetchPihole5Stats(instanceURL string, allowInsecure bool, token string, noG
 }
 
 func fetchPiholeStats(
+	ctx context.Context,
 	instanceURL string,
 	allowInsecure bool,
 	password string,
@@ -408,10 +410,10 @@ func fetchPiholeStats(
 	includeTopDomains bool,
 ) (*dnsStats, string, error) {
 	instanceURL = strings.TrimRight(instanceURL, "/")
-	var client = ternary(allowInsecure, defaultInsecureHTTPClient, defaultHTTPClient)
+	var client = widgetHTTPClient(ctx, allowInsecure)
 
 	fetchNewSessionID := func() error {
-		newSessionID, err := fetchPiholeSessionID(instanceURL, client, password)
+		newSessionID, err := fetchPiholeSessionID(ctx, instanceURL, client, password)
 		if err != nil {
 			return err
 		}
@@ -421,26 +423,26 @@ func fetchPiholeStats(
 
 	if sessionID == "" {
 		if err := fetchNewSessionID(); err != nil {
-			slog.Error("Failed to fetch Pihole v6 session ID", "error", err)
+			slog.ErrorContext(ctx, "Failed to fetch Pihole v6 session ID", "error", err)
 			return nil, "", fmt.Errorf("fetching session ID: %v", err)
 		}
 	} else {
-		isValid, err := checkPiholeSessionIDIsValid(instanceURL, client, sessionID)
+		isValid, err := checkPiholeSessionIDIsValid(ctx, instanceURL, client, sessionID)
 		if err != nil {
-			slog.Error("Failed to check Pihole v6 session ID validity", "error", err)
+			slog.ErrorContext(ctx, "Failed to check Pihole v6 session ID validity", "error", err)
 			return nil, "", fmt.Errorf("checking session ID: %v", err)
 		}
 
 		if !isValid {
 			if err := fetchNewSessionID(); err != nil {
-				slog.Error("Failed to renew Pihole v6 session ID", "error", err)
+				slog.ErrorContext(ctx, "Failed to renew Pihole v6 session ID", "error", err)
 				return nil, "", fmt.Errorf("renewing session ID: %v", err)
 			}
 		}
 	}
 
 	var wg sync.WaitGroup
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	type statsResponseJson struct {
@@ -523,12 +525,12 @@ func fetchPiholeStats(
 	}
 
 	if includeGraph && seriesErr != nil {
-		slog.Error("Failed to fetch Pihole v6 graph data", "error", seriesErr)
+		slog.ErrorContext(ctx, "Failed to fetch Pihole v6 graph data", "error", seriesErr)
 		partialContent = true
 	}
 
 	if includeTopDomains && topDomainsErr != nil {
-		slog.Error("Failed to fetch Pihole v6 top domains", "error", topDomainsErr)
+		slog.ErrorContext(ctx, "Failed to fetch Pihole v6 top domains", "error", topDomainsErr)
 		partialContent = true
 	}
 
@@ -541,7 +543,7 @@ func fetchPiholeStats(
 
 	if includeGraph && seriesErr == nil {
 		if len(seriesResponse.History) != 145 {
-			slog.Error(
+			slog.ErrorContext(ctx,
 				"Pihole v6 graph data has unexpected length",
 				"length", len(seriesResponse.History),
 				"expected", 145,
@@ -605,10 +607,10 @@ func fetchPiholeStats(
 	return stats, sessionID, ternary(partialContent, errPartialContent, nil)
 }
 
-func fetchPiholeSessionID(instanceURL string, client *http.Client, password string) (string, error) {
+func fetchPiholeSessionID(ctx context.Context, instanceURL string, client *http.Client, password string) (string, error) {
 	requestBody := []byte(`{"password":"` + password + `"}`)
 
-	request, err := http.NewRequest("POST", instanceURL+"/api/auth", bytes.NewBuffer(requestBody))
+	request, err := http.NewRequestWithContext(ctx, "POST", instanceURL+"/api/auth", bytes.NewBuffer(requestBody))
 	if err != nil {
 		return "", fmt.Errorf("creating authentication request: %v", err)
 	}
@@ -653,8 +655,8 @@ func fetchPiholeSessionID(instanceURL string, client *http.Client, password stri
 	return jsonResponse.Session.SID, nil
 }
 
-func checkPiholeSessionIDIsValid(instanceURL string, client *http.Client, sessionID string) (bool, error) {
-	request, err := http.NewRequest("GET", instanceURL+"/api/auth", nil)
+func checkPiholeSessionIDIsValid(ctx context.Context, instanceURL string, client *http.Client, sessionID string) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", instanceURL+"/api/auth", nil)
 	if err != nil {
 		return false, fmt.Errorf("creating session ID check request: %v", err)
 	}
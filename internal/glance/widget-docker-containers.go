@@ -32,11 +32,15 @@ func (widget *dockerContainersWidget) initialize() error {
 }
 
 func (widget *dockerContainersWidget) update(ctx context.Context) {
-	containers, err := fetchDockerContainers(widget.SockPath, widget.HideByDefault)
+	containers, err := fetchDockerContainers(ctx, widget.SockPath, widget.HideByDefault)
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
 	}
 
+	for i := range containers {
+		containers[i].Icon.URL = widget.Providers.userAssetResolver(containers[i].Icon.URL)
+	}
+
 	containers.sortByStateIconThenTitle()
 	widget.Containers = containers
 }
@@ -137,8 +141,8 @@ func dockerContainerStateToStateIcon(state string) string {
 	}
 }
 
-func fetchDockerContainers(socketPath string, hideByDefault bool) (dockerContainerList, error) {
-	containers, err := fetchAllDockerContainersFromSock(socketPath)
+func fetchDockerContainers(ctx context.Context, socketPath string, hideByDefault bool) (dockerContainerList, error) {
+	containers, err := fetchAllDockerContainersFromSock(ctx, socketPath)
 	if err != nil {
 		return nil, fmt.Errorf("fetching containers: %w", err)
 	}
@@ -239,17 +243,16 @@ func isDockerContainerHidden(container *dockerContainerJsonResponse, hideByDefau
 	return hideByDefault
 }
 
-func fetchAllDockerContainersFromSock(socketPath string) ([]dockerContainerJsonResponse, error) {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-		Transport: &http.Transport{
-			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
-				return net.Dial("unix", socketPath)
-			},
-		},
-	}
+func fetchAllDockerContainersFromSock(ctx context.Context, socketPath string) ([]dockerContainerJsonResponse, error) {
+	// the proxy from the config doesn't apply to connections over the socket
+	client := widgetHTTPClientsFromContext(ctx).newClient(5*time.Second, false, func(transport *http.Transport) {
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+		}
+	})
 
-	request, err := http.NewRequest("GET", "http://docker/containers/json?all=true", nil)
+	request, err := http.NewRequestWithContext(ctx, "GET", "http://docker/containers/json?all=true", nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -261,7 +264,7 @@ func fetchAllDockerContainersFromSock(socketPath string) ([]dockerContainerJsonR
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("non-200 response status: %s", response.Status)
+		return nil, &statusCodeError{response.StatusCode, fmt.Errorf("non-200 response status: %s", response.Status)}
 	}
 
 	var containers []dockerContainerJsonResponse
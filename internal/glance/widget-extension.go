@@ -43,7 +43,7 @@ func (widget *extensionWidget) initialize() error {
 }
 
 func (widget *extensionWidget) update(ctx context.Context) {
-	extension, err := fetchExtension(extensionRequestOptions{
+	extension, err := fetchExtension(ctx, extensionRequestOptions{
 		URL:                 widget.URL,
 		FallbackContentType: widget.FallbackContentType,
 		Parameters:          widget.Parameters,
@@ -67,7 +67,7 @@ func (widget *extensionWidget) update(ctx context.Context) {
 }
 
 func (widget *extensionWidget) Render() template.HTML {
-	return widget.cachedHTML
+	return widget.renderCachedTemplate(widget.cachedHTML, widget, extensionWidgetTemplate)
 }
 
 type extensionType int
@@ -116,8 +116,8 @@ func convertExtensionContent(options extensionRequestOptions, content []byte, co
 	}
 }
 
-func fetchExtension(options extensionRequestOptions) (extension, error) {
-	request, _ := http.NewRequest("GET", options.URL, nil)
+func fetchExtension(ctx context.Context, options extensionRequestOptions) (extension, error) {
+	request, _ := http.NewRequestWithContext(ctx, "GET", options.URL, nil)
 	if len(options.Parameters) > 0 {
 		request.URL.RawQuery = options.Parameters.toQueryString()
 	}
@@ -126,9 +126,9 @@ func fetchExtension(options extensionRequestOptions) (extension, error) {
 		request.Header.Add(key, value)
 	}
 
-	response, err := http.DefaultClient.Do(request)
+	response, err := widgetHTTPClientsFromContext(ctx).extension.Do(request)
 	if err != nil {
-		slog.Error("Failed fetching extension", "url", options.URL, "error", err)
+		slog.ErrorContext(ctx, "Failed fetching extension", "url", options.URL, "error", err)
 		return extension{}, fmt.Errorf("%w: request failed: %w", errNoContent, err)
 	}
 
@@ -136,7 +136,7 @@ func fetchExtension(options extensionRequestOptions) (extension, error) {
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		slog.Error("Failed reading response body of extension", "url", options.URL, "error", err)
+		slog.ErrorContext(ctx, "Failed reading response body of extension", "url", options.URL, "error", err)
 		return extension{}, fmt.Errorf("%w: could not read body: %w", errNoContent, err)
 	}
 
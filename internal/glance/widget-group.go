@@ -18,6 +18,10 @@ func (widget *groupWidget) initialize() error {
 	widget.withError(nil)
 	widget.HideHeader = true
 
+	if len(widget.Widgets) == 0 {
+		return errors.New("must contain at least one widget")
+	}
+
 	for i := range widget.Widgets {
 		widget.Widgets[i].setHideHeader(true)
 
@@ -39,12 +43,16 @@ func (widget *groupWidget) update(ctx context.Context) {
 	widget.containerWidgetBase._update(ctx)
 }
 
+func (widget *groupWidget) getTimeout() time.Duration {
+	return 0
+}
+
 func (widget *groupWidget) setProviders(providers *widgetProviders) {
 	widget.containerWidgetBase._setProviders(providers)
 }
 
 func (widget *groupWidget) requiresUpdate(now *time.Time) bool {
-	return widget.containerWidgetBase._requiresUpdate(now)
+	return !widget.isOutsideSchedule(*now) && widget.containerWidgetBase._requiresUpdate(now)
 }
 
 func (widget *groupWidget) Render() template.HTML {
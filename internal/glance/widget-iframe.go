@@ -39,5 +39,5 @@ func (widget *iframeWidget) initialize() error {
 }
 
 func (widget *iframeWidget) Render() template.HTML {
-	return widget.cachedHTML
+	return widget.renderCachedTemplate(widget.cachedHTML, widget, iframeWidgetTemplate)
 }
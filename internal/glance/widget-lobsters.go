@@ -45,7 +45,7 @@ func (widget *lobstersWidget) initialize() error {
 }
 
 func (widget *lobstersWidget) update(ctx context.Context) {
-	posts, err := fetchLobstersPosts(widget.CustomURL, widget.InstanceURL, widget.SortBy, widget.Tags)
+	posts, err := fetchLobstersPosts(ctx, widget.CustomURL, widget.InstanceURL, widget.SortBy, widget.Tags)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
@@ -74,13 +74,13 @@ type lobstersPostResponseJson struct {
 
 type lobstersFeedResponseJson []lobstersPostResponseJson
 
-func fetchLobstersPostsFromFeed(feedUrl string) (forumPostList, error) {
-	request, err := http.NewRequest("GET", feedUrl, nil)
+func fetchLobstersPostsFromFeed(ctx context.Context, feedUrl string) (forumPostList, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", feedUrl, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	feed, err := decodeJsonFromRequest[lobstersFeedResponseJson](defaultHTTPClient, request)
+	feed, err := decodeJsonFromRequest[lobstersFeedResponseJson](widgetHTTPClient(ctx, false), request)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +109,7 @@ func fetchLobstersPostsFromFeed(feedUrl string) (forumPostList, error) {
 	return posts, nil
 }
 
-func fetchLobstersPosts(customURL string, instanceURL string, sortBy string, tags []string) (forumPostList, error) {
+func fetchLobstersPosts(ctx context.Context, customURL string, instanceURL string, sortBy string, tags []string) (forumPostList, error) {
 	var feedUrl string
 
 	if customURL != "" {
@@ -135,7 +135,7 @@ func fetchLobstersPosts(customURL string, instanceURL string, sortBy string, tag
 		}
 	}
 
-	posts, err := fetchLobstersPostsFromFeed(feedUrl)
+	posts, err := fetchLobstersPostsFromFeed(ctx, feedUrl)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,164 @@
+package glance
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Implemented by widgets that have a fixed set of fake data to show instead of
+// fetching from their source when mock is enabled. The data is assigned the
+// same way an update would, so the rest of the widget doesn't know the difference.
+type mockableWidget interface {
+	mock()
+}
+
+// Widgets without mock data of their own are left empty and show a placeholder
+// instead, since their templates generally expect the data of a successful update
+func updateWidgetWithMockData(widget widget) {
+	mockable, ok := widget.(mockableWidget)
+	if ok {
+		mockable.mock()
+	}
+
+	widget.completeMockedUpdate(ok)
+}
+
+func mockForumPosts(limit int) forumPostList {
+	now := time.Now()
+	titles := []string{
+		"Show HN: A self-hosted dashboard that puts all your feeds in one place",
+		"Why we moved our build system back to Makefiles",
+		"The surprising complexity of parsing dates",
+		"A visual guide to how TLS handshakes work",
+		"Ask HN: What is your home lab running these days?",
+		"Writing a tiny database from scratch",
+		"Lessons learned from ten years of maintaining an open source project",
+		"How terminals render text",
+	}
+
+	posts := make(forumPostList, 0, min(limit, len(titles)))
+	for i := 0; i < len(titles) && i < limit; i++ {
+		posts = append(posts, forumPost{
+			Title:           titles[i],
+			DiscussionUrl:   fmt.Sprintf("https://example.com/discussion/%d", i+1),
+			TargetUrl:       fmt.Sprintf("https://example.com/articles/%d", i+1),
+			TargetUrlDomain: "example.com",
+			CommentCount:    240 - i*27,
+			Score:           610 - i*64,
+			TimePosted:      now.Add(-time.Duration(i*2+1) * time.Hour),
+		})
+	}
+
+	return posts
+}
+
+func (widget *hackerNewsWidget) mock() {
+	widget.Posts = mockForumPosts(widget.Limit)
+}
+
+func (widget *lobstersWidget) mock() {
+	widget.Posts = mockForumPosts(widget.Limit)
+}
+
+func (widget *redditWidget) mock() {
+	widget.Posts = mockForumPosts(widget.Limit)
+}
+
+func (widget *rssWidget) mock() {
+	now := time.Now()
+	titles := []string{
+		"Release notes for the latest version",
+		"A look back at the year in open source",
+		"Getting started with container networking",
+		"Ten small tools that made my workflow better",
+		"Understanding memory allocators",
+	}
+
+	items := make(rssFeedItemList, 0, min(widget.Limit, len(titles)))
+	for i := 0; i < len(titles) && i < widget.Limit; i++ {
+		items = append(items, rssFeedItem{
+			ChannelName: "Example Blog",
+			ChannelURL:  "https://example.com",
+			Title:       titles[i],
+			Link:        fmt.Sprintf("https://example.com/posts/%d", i+1),
+			Description: "A short summary of the post, long enough to show how descriptions wrap inside of the widget.",
+			PublishedAt: now.Add(-time.Duration(i*9+3) * time.Hour),
+		})
+	}
+
+	widget.Items = items
+}
+
+func (widget *releasesWidget) mock() {
+	now := time.Now()
+	releases := appReleaseList{
+		{Source: releaseSourceGithub, Name: "glanceapp/glance", Version: "v0.8.0", TimeReleased: now.Add(-20 * time.Hour)},
+		{Source: releaseSourceGithub, Name: "immich-app/immich", Version: "v1.130.0", TimeReleased: now.Add(-3 * 24 * time.Hour)},
+		{Source: releaseSourceDockerHub, Name: "library/postgres", Version: "17.4", TimeReleased: now.Add(-9 * 24 * time.Hour)},
+		{Source: releaseSourceGitlab, Name: "fdroid/fdroidclient", Version: "1.22.0", TimeReleased: now.Add(-26 * 24 * time.Hour)},
+	}
+
+	if len(releases) > widget.Limit {
+		releases = releases[:widget.Limit]
+	}
+
+	for i := range releases {
+		releases[i].NotesUrl = "https://example.com/releases/" + releases[i].Version
+		releases[i].SourceIconURL = widget.Providers.assetResolver("icons/" + string(releases[i].Source) + ".svg")
+	}
+
+	widget.Releases = releases
+}
+
+func (widget *monitorWidget) mock() {
+	widget.HasFailing = false
+
+	for i := range widget.Sites {
+		site := &widget.Sites[i]
+		site.Status = &siteStatus{
+			Code:         200,
+			ResponseTime: time.Duration(40+i*15) * time.Millisecond,
+		}
+		site.URL = site.DefaultURL
+		site.StatusText = statusCodeToText(site.Status.Code, site.AltStatusCodes)
+		site.StatusStyle = statusCodeToStyle(site.Status.Code, site.AltStatusCodes)
+	}
+}
+
+func (widget *marketsWidget) mock() {
+	requests := widget.MarketRequests
+	if len(requests) == 0 {
+		requests = []marketRequest{
+			{CustomName: "S&P 500", Symbol: "SPY"},
+			{CustomName: "Bitcoin", Symbol: "BTC-USD"},
+			{CustomName: "Apple", Symbol: "AAPL"},
+		}
+	}
+
+	markets := make(marketList, len(requests))
+	for i := range requests {
+		prices := make([]float64, 21)
+		for p := range prices {
+			prices[p] = 100 + 10*math.Sin(float64(p+i*3)/3)
+		}
+
+		markets[i] = market{
+			marketRequest:  requests[i],
+			Name:           ternary(requests[i].CustomName == "", requests[i].Symbol, requests[i].CustomName),
+			Currency:       "$",
+			Price:          prices[len(prices)-1] * float64(i+1),
+			PriceHint:      2,
+			PercentChange:  percentChange(prices[len(prices)-1], prices[len(prices)-2]),
+			SvgChartPoints: svgPolylineCoordsFromYValues(100, 50, prices),
+		}
+	}
+
+	if widget.Sort == "absolute-change" {
+		markets.sortByAbsChange()
+	} else if widget.Sort == "change" {
+		markets.sortByChange()
+	}
+
+	widget.Markets = markets
+}
@@ -40,6 +40,14 @@ func (widget *monitorWidget) initialize() error {
 	return nil
 }
 
+func (widget *monitorWidget) setProviders(providers *widgetProviders) {
+	widget.widgetBase.setProviders(providers)
+
+	for i := range widget.Sites {
+		widget.Sites[i].Icon.URL = providers.userAssetResolver(widget.Sites[i].Icon.URL)
+	}
+}
+
 func (widget *monitorWidget) update(ctx context.Context) {
 	requests := make([]*SiteStatusRequest, len(widget.Sites))
 
@@ -47,7 +55,7 @@ func (widget *monitorWidget) update(ctx context.Context) {
 		requests[i] = widget.Sites[i].SiteStatusRequest
 	}
 
-	statuses, err := fetchStatusForSites(requests)
+	statuses, err := fetchStatusForSites(ctx, requests)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
@@ -127,31 +135,27 @@ type siteStatus struct {
 	Error        error
 }
 
-func fetchSiteStatusTask(statusRequest *SiteStatusRequest) (siteStatus, error) {
+func fetchSiteStatusTask(ctx context.Context, statusRequest *SiteStatusRequest) (siteStatus, error) {
 	var url string
 	if statusRequest.CheckURL != "" {
 		url = statusRequest.CheckURL
 	} else {
 		url = statusRequest.DefaultURL
 	}
-	request, err := http.NewRequest(http.MethodGet, url, nil)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return siteStatus{
 			Error: err,
 		}, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	// the values of the widget's context are kept since they include the clients and headers
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second*3)
 	defer cancel()
 	request = request.WithContext(ctx)
 	requestSentAt := time.Now()
-	var response *http.Response
 
-	if !statusRequest.AllowInsecure {
-		response, err = defaultHTTPClient.Do(request)
-	} else {
-		response, err = defaultInsecureHTTPClient.Do(request)
-	}
+	response, err := widgetHTTPClient(ctx, statusRequest.AllowInsecure).Do(request)
 
 	status := siteStatus{ResponseTime: time.Since(requestSentAt)}
 
@@ -171,8 +175,10 @@ func fetchSiteStatusTask(statusRequest *SiteStatusRequest) (siteStatus, error) {
 	return status, nil
 }
 
-func fetchStatusForSites(requests []*SiteStatusRequest) ([]siteStatus, error) {
-	job := newJob(fetchSiteStatusTask, requests).withWorkers(20)
+func fetchStatusForSites(ctx context.Context, requests []*SiteStatusRequest) ([]siteStatus, error) {
+	job := newJob(func(request *SiteStatusRequest) (siteStatus, error) {
+		return fetchSiteStatusTask(ctx, request)
+	}, requests).withWorkers(20)
 	results, _, err := workerPoolDo(job)
 	if err != nil {
 		return nil, err
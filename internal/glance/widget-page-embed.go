@@ -0,0 +1,117 @@
+package glance
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+var pageEmbedWidgetTemplate = mustParseTemplate("page-embed.html", "widget-base.html")
+
+type pageEmbedWidget struct {
+	widgetBase `yaml:",inline"`
+	cachedHTML template.HTML `yaml:"-"`
+	Page       string        `yaml:"page"`
+	Height     int           `yaml:"height"`
+	// set once the page has been checked to exist
+	Source string `yaml:"-"`
+}
+
+func (widget *pageEmbedWidget) initialize() error {
+	widget.withTitle("").withError(nil)
+
+	if widget.Page == "" {
+		return errors.New("page is required")
+	}
+
+	// 0 means that the height follows the content of the page
+	if widget.Height != 0 && widget.Height < 50 {
+		widget.Height = 50
+	}
+
+	widget.cachedHTML = widget.renderTemplate(widget, pageEmbedWidgetTemplate)
+
+	return nil
+}
+
+func (widget *pageEmbedWidget) Render() template.HTML {
+	return widget.renderCachedTemplate(widget.cachedHTML, widget, pageEmbedWidgetTemplate)
+}
+
+// Checks that every embedded page exists and that no page ends up embedding itself, either
+// directly or through the pages it embeds, which would otherwise keep nesting iframes forever
+func resolvePageEmbeds(c *config, baseURL string) error {
+	embeds := make(map[string][]string, len(c.Pages))
+	resolved := make(map[widget]struct{})
+
+	for p := range c.Pages {
+		page := &c.Pages[p]
+
+		for col := range page.Columns {
+			for _, widget := range findPageEmbedWidgets(page.Columns[col].Widgets) {
+				if widget.Page == "" {
+					continue
+				}
+
+				target, exists := c.PageBySlug(widget.Page)
+				if !exists {
+					return fmt.Errorf("page %d: page-embed widget: unknown page %q", p+1, widget.Page)
+				}
+
+				if target == page {
+					return fmt.Errorf("page %d: page-embed widget: a page cannot embed itself", p+1)
+				}
+
+				embeds[page.Slug] = append(embeds[page.Slug], target.Slug)
+
+				if _, done := resolved[widget]; !done {
+					resolved[widget] = struct{}{}
+					widget.Source = baseURL + "/" + url.PathEscape(target.Slug) + "?embed"
+				}
+			}
+		}
+	}
+
+	for p := range c.Pages {
+		if cycle := findPageEmbedCycle(embeds, c.Pages[p].Slug, nil); cycle != nil {
+			return fmt.Errorf("page-embed widget: pages embed each other in a loop: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	return nil
+}
+
+func findPageEmbedWidgets(list widgets) []*pageEmbedWidget {
+	var result []*pageEmbedWidget
+
+	for _, w := range list {
+		if embed, ok := w.(*pageEmbedWidget); ok {
+			result = append(result, embed)
+		} else if container, ok := w.(widgetContainer); ok {
+			result = append(result, findPageEmbedWidgets(container.containedWidgets())...)
+		}
+	}
+
+	return result
+}
+
+// Returns the slugs of the pages making up the loop, starting and ending with the given one
+func findPageEmbedCycle(embeds map[string][]string, slug string, path []string) []string {
+	for i := range path {
+		if path[i] == slug {
+			return append(path[i:], slug)
+		}
+	}
+
+	path = append(path, slug)
+
+	for _, embedded := range embeds[slug] {
+		if cycle := findPageEmbedCycle(embeds, embedded, path); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
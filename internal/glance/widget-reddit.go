@@ -86,9 +86,15 @@ func isValidRedditTopPeriod(period string) bool {
 		period == "all"
 }
 
+func (widget *redditWidget) setProviders(providers *widgetProviders) {
+	widget.widgetBase.setProviders(providers)
+	widget.Proxy.setClientFrom(providers.httpClients)
+}
+
 func (widget *redditWidget) update(ctx context.Context) {
 	// TODO: refactor, use a struct to pass all of these
 	posts, err := fetchSubredditPosts(
+		ctx,
 		widget.Subreddit,
 		widget.SortBy,
 		widget.TopPeriod,
@@ -164,6 +170,7 @@ func templateRedditCommentsURL(template, subreddit, postId, postPath string) str
 }
 
 func fetchSubredditPosts(
+	ctx context.Context,
 	subreddit,
 	sort,
 	topPeriod,
@@ -191,7 +198,7 @@ func fetchSubredditPosts(
 		requestUrl = fmt.Sprintf("https://www.reddit.com/r/%s/%s.json?%s", subreddit, sort, query.Encode())
 	}
 
-	var client requestDoer = defaultHTTPClient
+	var client requestDoer = widgetHTTPClient(ctx, false)
 
 	if requestUrlTemplate != "" {
 		requestUrl = strings.ReplaceAll(requestUrlTemplate, "{REQUEST-URL}", requestUrl)
@@ -199,7 +206,7 @@ func fetchSubredditPosts(
 		client = proxyClient
 	}
 
-	request, err := http.NewRequest("GET", requestUrl, nil)
+	request, err := http.NewRequestWithContext(ctx, "GET", requestUrl, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +53,7 @@ func (widget *releasesWidget) initialize() error {
 }
 
 func (widget *releasesWidget) update(ctx context.Context) {
-	releases, err := fetchLatestReleases(widget.Repositories)
+	releases, err := fetchLatestReleases(ctx, widget.Repositories)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
@@ -153,8 +153,10 @@ func (r *releaseRequest) UnmarshalYAML(node *yaml.Node) error {
 	return nil
 }
 
-func fetchLatestReleases(requests []*releaseRequest) (appReleaseList, error) {
-	job := newJob(fetchLatestReleaseTask, requests).withWorkers(20)
+func fetchLatestReleases(ctx context.Context, requests []*releaseRequest) (appReleaseList, error) {
+	job := newJob(func(request *releaseRequest) (*appRelease, error) {
+		return fetchLatestReleaseTask(ctx, request)
+	}, requests).withWorkers(20)
 	results, errs, err := workerPoolDo(job)
 	if err != nil {
 		return nil, err
@@ -167,7 +169,7 @@ func fetchLatestReleases(requests []*releaseRequest) (appReleaseList, error) {
 	for i := range results {
 		if errs[i] != nil {
 			failed++
-			slog.Error("Failed to fetch release", "source", requests[i].source, "repository", requests[i].Repository, "error", errs[i])
+			slog.ErrorContext(ctx, "Failed to fetch release", "source", requests[i].source, "repository", requests[i].Repository, "error", errs[i])
 			continue
 		}
 
@@ -187,16 +189,16 @@ func fetchLatestReleases(requests []*releaseRequest) (appReleaseList, error) {
 	return releases, nil
 }
 
-func fetchLatestReleaseTask(request *releaseRequest) (*appRelease, error) {
+func fetchLatestReleaseTask(ctx context.Context, request *releaseRequest) (*appRelease, error) {
 	switch request.source {
 	case releaseSourceCodeberg:
-		return fetchLatestCodebergRelease(request)
+		return fetchLatestCodebergRelease(ctx, request)
 	case releaseSourceGithub:
-		return fetchLatestGithubRelease(request)
+		return fetchLatestGithubRelease(ctx, request)
 	case releaseSourceGitlab:
-		return fetchLatestGitLabRelease(request)
+		return fetchLatestGitLabRelease(ctx, request)
 	case releaseSourceDockerHub:
-		return fetchLatestDockerHubRelease(request)
+		return fetchLatestDockerHubRelease(ctx, request)
 	}
 
 	return nil, errors.New("unsupported source")
@@ -211,7 +213,7 @@ type githubReleaseResponseJson struct {
 	} `json:"reactions"`
 }
 
-func fetchLatestGithubRelease(request *releaseRequest) (*appRelease, error) {
+func fetchLatestGithubRelease(ctx context.Context, request *releaseRequest) (*appRelease, error) {
 	var requestURL string
 	if !request.IncludePreleases {
 		requestURL = fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", request.Repository)
@@ -219,7 +221,7 @@ func fetchLatestGithubRelease(request *releaseRequest) (*appRelease, error) {
 		requestURL = fmt.Sprintf("https://api.github.com/repos/%s/releases", request.Repository)
 	}
 
-	httpRequest, err := http.NewRequest("GET", requestURL, nil)
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -231,12 +233,12 @@ func fetchLatestGithubRelease(request *releaseRequest) (*appRelease, error) {
 	var response githubReleaseResponseJson
 
 	if !request.IncludePreleases {
-		response, err = decodeJsonFromRequest[githubReleaseResponseJson](defaultHTTPClient, httpRequest)
+		response, err = decodeJsonFromRequest[githubReleaseResponseJson](widgetHTTPClient(ctx, false), httpRequest)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		responses, err := decodeJsonFromRequest[[]githubReleaseResponseJson](defaultHTTPClient, httpRequest)
+		responses, err := decodeJsonFromRequest[[]githubReleaseResponseJson](widgetHTTPClient(ctx, false), httpRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -272,7 +274,7 @@ const dockerHubRepoTagURLFormat = "https://hub.docker.com/r/%s/tags?name=%s"
 const dockerHubTagsURLFormat = "https://hub.docker.com/v2/namespaces/%s/repositories/%s/tags"
 const dockerHubSpecificTagURLFormat = "https://hub.docker.com/v2/namespaces/%s/repositories/%s/tags/%s"
 
-func fetchLatestDockerHubRelease(request *releaseRequest) (*appRelease, error) {
+func fetchLatestDockerHubRelease(ctx context.Context, request *releaseRequest) (*appRelease, error) {
 	nameParts := strings.Split(request.Repository, "/")
 
 	if len(nameParts) > 2 {
@@ -290,7 +292,7 @@ func fetchLatestDockerHubRelease(request *releaseRequest) (*appRelease, error) {
 		requestURL = fmt.Sprintf(dockerHubTagsURLFormat, nameParts[0], nameParts[1])
 	}
 
-	httpRequest, err := http.NewRequest("GET", requestURL, nil)
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -302,7 +304,7 @@ func fetchLatestDockerHubRelease(request *releaseRequest) (*appRelease, error) {
 	var tag *dockerHubRepositoryTagResponse
 
 	if len(tagParts) == 1 {
-		response, err := decodeJsonFromRequest[dockerHubRepositoryTagsResponse](defaultHTTPClient, httpRequest)
+		response, err := decodeJsonFromRequest[dockerHubRepositoryTagsResponse](widgetHTTPClient(ctx, false), httpRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -313,7 +315,7 @@ func fetchLatestDockerHubRelease(request *releaseRequest) (*appRelease, error) {
 
 		tag = &response.Results[0]
 	} else {
-		response, err := decodeJsonFromRequest[dockerHubRepositoryTagResponse](defaultHTTPClient, httpRequest)
+		response, err := decodeJsonFromRequest[dockerHubRepositoryTagResponse](widgetHTTPClient(ctx, false), httpRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -356,8 +358,9 @@ type gitlabReleaseResponseJson struct {
 	} `json:"_links"`
 }
 
-func fetchLatestGitLabRelease(request *releaseRequest) (*appRelease, error) {
-	httpRequest, err := http.NewRequest(
+func fetchLatestGitLabRelease(ctx context.Context, request *releaseRequest) (*appRelease, error) {
+	httpRequest, err := http.NewRequestWithContext(
+		ctx,
 		"GET",
 		fmt.Sprintf(
 			"https://gitlab.com/api/v4/projects/%s/releases/permalink/latest",
@@ -373,7 +376,7 @@ func fetchLatestGitLabRelease(request *releaseRequest) (*appRelease, error) {
 		httpRequest.Header.Add("PRIVATE-TOKEN", *request.token)
 	}
 
-	response, err := decodeJsonFromRequest[gitlabReleaseResponseJson](defaultHTTPClient, httpRequest)
+	response, err := decodeJsonFromRequest[gitlabReleaseResponseJson](widgetHTTPClient(ctx, false), httpRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -393,8 +396,9 @@ type codebergReleaseResponseJson struct {
 	HtmlUrl     string `json:"html_url"`
 }
 
-func fetchLatestCodebergRelease(request *releaseRequest) (*appRelease, error) {
-	httpRequest, err := http.NewRequest(
+func fetchLatestCodebergRelease(ctx context.Context, request *releaseRequest) (*appRelease, error) {
+	httpRequest, err := http.NewRequestWithContext(
+		ctx,
 		"GET",
 		fmt.Sprintf(
 			"https://codeberg.org/api/v1/repos/%s/releases/latest",
@@ -406,7 +410,7 @@ func fetchLatestCodebergRelease(request *releaseRequest) (*appRelease, error) {
 		return nil, err
 	}
 
-	response, err := decodeJsonFromRequest[codebergReleaseResponseJson](defaultHTTPClient, httpRequest)
+	response, err := decodeJsonFromRequest[codebergReleaseResponseJson](widgetHTTPClient(ctx, false), httpRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +70,7 @@ func (widget *rssWidget) initialize() error {
 }
 
 func (widget *rssWidget) update(ctx context.Context) {
-	items, err := fetchItemsFromRSSFeeds(widget.FeedRequests)
+	items, err := fetchItemsFromRSSFeeds(ctx, widget.FeedRequests)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
@@ -166,8 +166,8 @@ func (f rssFeedItemList) sortByNewest() rssFeedItemList {
 
 var feedParser = gofeed.NewParser()
 
-func fetchItemsFromRSSFeedTask(request rssFeedRequest) ([]rssFeedItem, error) {
-	req, err := http.NewRequest("GET", request.URL, nil)
+func fetchItemsFromRSSFeedTask(ctx context.Context, request rssFeedRequest) ([]rssFeedItem, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", request.URL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -176,14 +176,14 @@ func fetchItemsFromRSSFeedTask(request rssFeedRequest) ([]rssFeedItem, error) {
 		req.Header.Add(key, value)
 	}
 
-	resp, err := defaultHTTPClient.Do(req)
+	resp, err := widgetHTTPClient(ctx, false).Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, request.URL)
+		return nil, &statusCodeError{resp.StatusCode, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, request.URL)}
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -322,8 +322,12 @@ func findThumbnailInItemExtensions(item *gofeed.Item) string {
 	return recursiveFindThumbnailInExtensions(media)
 }
 
-func fetchItemsFromRSSFeeds(requests []rssFeedRequest) (rssFeedItemList, error) {
-	job := newJob(fetchItemsFromRSSFeedTask, requests).withWorkers(30)
+func fetchItemsFromRSSFeeds(ctx context.Context, requests []rssFeedRequest) (rssFeedItemList, error) {
+	task := func(request rssFeedRequest) ([]rssFeedItem, error) {
+		return fetchItemsFromRSSFeedTask(ctx, request)
+	}
+
+	job := newJob(task, requests).withWorkers(30)
 	feeds, errs, err := workerPoolDo(job)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", errNoContent, err)
@@ -335,7 +339,7 @@ func fetchItemsFromRSSFeeds(requests []rssFeedRequest) (rssFeedItemList, error)
 	for i := range feeds {
 		if errs[i] != nil {
 			failed++
-			slog.Error("Failed to get RSS feed", "url", requests[i].URL, "error", errs[i])
+			slog.ErrorContext(ctx, "Failed to get RSS feed", "url", requests[i].URL, "error", errs[i])
 			continue
 		}
 
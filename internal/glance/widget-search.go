@@ -69,5 +69,5 @@ func (widget *searchWidget) initialize() error {
 }
 
 func (widget *searchWidget) Render() template.HTML {
-	return widget.cachedHTML
+	return widget.renderCachedTemplate(widget.cachedHTML, widget, searchWidgetTemplate)
 }
@@ -39,7 +39,7 @@ func (widget *serverStatsWidget) initialize() error {
 	return nil
 }
 
-func (widget *serverStatsWidget) update(context.Context) {
+func (widget *serverStatsWidget) update(ctx context.Context) {
 	// Refactor later, most of it may change depending on feedback
 	var wg sync.WaitGroup
 
@@ -51,7 +51,7 @@ func (widget *serverStatsWidget) update(context.Context) {
 
 			if len(errs) > 0 {
 				for i := range errs {
-					slog.Warn("Getting system info: " + errs[i].Error())
+					slog.WarnContext(ctx, "Getting system info: "+errs[i].Error())
 				}
 			}
 
@@ -61,9 +61,9 @@ func (widget *serverStatsWidget) update(context.Context) {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				info, err := fetchRemoteServerInfo(serv)
+				info, err := fetchRemoteServerInfo(ctx, serv)
 				if err != nil {
-					slog.Warn("Getting remote system info: " + err.Error())
+					slog.WarnContext(ctx, "Getting remote system info: "+err.Error())
 					serv.IsReachable = false
 					serv.Info = &sysinfo.SystemInfo{
 						Hostname: "Unnamed server #" + strconv.Itoa(i+1),
@@ -99,8 +99,8 @@ type serverStatsRequest struct {
 	// Provider                   string              `yaml:"provider"`
 }
 
-func fetchRemoteServerInfo(infoReq *serverStatsRequest) (*sysinfo.SystemInfo, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(infoReq.Timeout))
+func fetchRemoteServerInfo(ctx context.Context, infoReq *serverStatsRequest) (*sysinfo.SystemInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(infoReq.Timeout))
 	defer cancel()
 
 	request, _ := http.NewRequestWithContext(ctx, "GET", infoReq.URL+"/api/sysinfo/all", nil)
@@ -108,7 +108,7 @@ func fetchRemoteServerInfo(infoReq *serverStatsRequest) (*sysinfo.SystemInfo, er
 		request.Header.Set("Authorization", "Bearer "+infoReq.Token)
 	}
 
-	info, err := decodeJsonFromRequest[*sysinfo.SystemInfo](defaultHTTPClient, request)
+	info, err := decodeJsonFromRequest[*sysinfo.SystemInfo](widgetHTTPClient(ctx, false), request)
 	if err != nil {
 		return nil, err
 	}
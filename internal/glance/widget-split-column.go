@@ -32,12 +32,16 @@ func (widget *splitColumnWidget) update(ctx context.Context) {
 	widget.containerWidgetBase._update(ctx)
 }
 
+func (widget *splitColumnWidget) getTimeout() time.Duration {
+	return 0
+}
+
 func (widget *splitColumnWidget) setProviders(providers *widgetProviders) {
 	widget.containerWidgetBase._setProviders(providers)
 }
 
 func (widget *splitColumnWidget) requiresUpdate(now *time.Time) bool {
-	return widget.containerWidgetBase._requiresUpdate(now)
+	return !widget.isOutsideSchedule(*now) && widget.containerWidgetBase._requiresUpdate(now)
 }
 
 func (widget *splitColumnWidget) Render() template.HTML {
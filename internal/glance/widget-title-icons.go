@@ -0,0 +1,65 @@
+package glance
+
+import "html/template"
+
+// Shown next to or instead of the title of widgets depending on the widget-title-tag of their column
+const (
+	widgetIconClock    = `<circle cx="12" cy="12" r="9" /><path d="M12 7v5l3 2" />`
+	widgetIconCalendar = `<rect x="3" y="5" width="18" height="16" rx="2" /><path d="M3 10h18M8 3v4M16 3v4" />`
+	widgetIconWeather  = `<circle cx="12" cy="12" r="4" /><path d="M12 2v2M12 20v2M2 12h2M20 12h2M4.9 4.9l1.4 1.4M17.7 17.7l1.4 1.4M4.9 19.1l1.4-1.4M17.7 6.3l1.4-1.4" />`
+	widgetIconBookmark = `<path d="M6 3h12v18l-6-4-6 4Z" />`
+	widgetIconCode     = `<path d="M8 7l-5 5 5 5M16 7l5 5-5 5" />`
+	widgetIconFeed     = `<circle cx="5" cy="19" r="1.5" /><path d="M4 11a9 9 0 0 1 9 9M4 4a16 16 0 0 1 16 16" />`
+	widgetIconRelease  = `<path d="M3 12V3h9l9 9-9 9Z" /><circle cx="7.5" cy="7.5" r="1.5" />`
+	widgetIconBranch   = `<circle cx="6" cy="5" r="2" /><circle cx="6" cy="19" r="2" /><circle cx="18" cy="7" r="2" /><path d="M6 7v10M18 9c0 5-12 3-12 8" />`
+	widgetIconVideo    = `<rect x="3" y="5" width="18" height="14" rx="2" /><path d="M10 9v6l5-3Z" />`
+	widgetIconChart    = `<path d="M3 3v18h18" /><path d="M7 15l4-4 3 3 6-7" />`
+	widgetIconServer   = `<rect x="3" y="4" width="18" height="7" rx="1.5" /><rect x="3" y="13" width="18" height="7" rx="1.5" /><path d="M7 7.5h.01M7 16.5h.01" />`
+	widgetIconEye      = `<path d="M2 12s3.5-7 10-7 10 7 10 7-3.5 7-10 7S2 12 2 12Z" /><circle cx="12" cy="12" r="3" />`
+	widgetIconSearch   = `<circle cx="11" cy="11" r="7" /><path d="M20 20l-4-4" />`
+	widgetIconGrid     = `<rect x="3" y="3" width="7" height="7" rx="1" /><rect x="14" y="3" width="7" height="7" rx="1" /><rect x="3" y="14" width="7" height="7" rx="1" /><rect x="14" y="14" width="7" height="7" rx="1" />`
+)
+
+var widgetTitleIcons = map[string]string{
+	"calendar":          widgetIconCalendar,
+	"calendar-legacy":   widgetIconCalendar,
+	"clock":             widgetIconClock,
+	"weather":           widgetIconWeather,
+	"bookmarks":         widgetIconBookmark,
+	"iframe":            widgetIconCode,
+	"page-embed":        widgetIconCode,
+	"html":              widgetIconCode,
+	"hacker-news":       widgetIconFeed,
+	"releases":          widgetIconRelease,
+	"videos":            widgetIconVideo,
+	"markets":           widgetIconChart,
+	"stocks":            widgetIconChart,
+	"reddit":            widgetIconFeed,
+	"rss":               widgetIconFeed,
+	"monitor":           widgetIconServer,
+	"twitch-top-games":  widgetIconVideo,
+	"twitch-channels":   widgetIconVideo,
+	"lobsters":          widgetIconFeed,
+	"change-detection":  widgetIconEye,
+	"repository":        widgetIconBranch,
+	"search":            widgetIconSearch,
+	"extension":         widgetIconCode,
+	"group":             widgetIconGrid,
+	"dns-stats":         widgetIconServer,
+	"split-column":      widgetIconGrid,
+	"custom-api":        widgetIconCode,
+	"docker-containers": widgetIconServer,
+	"server-stats":      widgetIconServer,
+}
+
+func widgetTitleIcon(widgetType string) template.HTML {
+	icon, ok := widgetTitleIcons[widgetType]
+	if !ok {
+		icon = widgetIconGrid
+	}
+
+	return template.HTML(
+		`<svg class="widget-title-icon" xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="1.5" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true">` +
+			icon + `</svg>`,
+	)
+}
@@ -40,7 +40,7 @@ func (widget *twitchChannelsWidget) initialize() error {
 }
 
 func (widget *twitchChannelsWidget) update(ctx context.Context) {
-	channels, err := fetchChannelsFromTwitch(widget.ChannelsRequest)
+	channels, err := fetchChannelsFromTwitch(ctx, widget.ChannelsRequest)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
@@ -130,16 +130,16 @@ const twitchChannelStatusOperationRequestBody = `[
 // what the limit is for max operations per request and batch operations in
 // multiple requests if number of channels exceeds allowed limit.
 
-func fetchChannelFromTwitchTask(channel string) (twitchChannel, error) {
+func fetchChannelFromTwitchTask(ctx context.Context, channel string) (twitchChannel, error) {
 	result := twitchChannel{
 		Login: strings.ToLower(channel),
 	}
 
 	reader := strings.NewReader(fmt.Sprintf(twitchChannelStatusOperationRequestBody, channel, channel))
-	request, _ := http.NewRequest("POST", twitchGqlEndpoint, reader)
+	request, _ := http.NewRequestWithContext(ctx, "POST", twitchGqlEndpoint, reader)
 	request.Header.Add("Client-ID", twitchGqlClientId)
 
-	response, err := decodeJsonFromRequest[[]twitchOperationResponse](defaultHTTPClient, request)
+	response, err := decodeJsonFromRequest[[]twitchOperationResponse](widgetHTTPClient(ctx, false), request)
 	if err != nil {
 		return result, err
 	}
@@ -193,7 +193,7 @@ func fetchChannelFromTwitchTask(channel string) (twitchChannel, error) {
 			if err == nil {
 				result.LiveSince = startedAt
 			} else {
-				slog.Warn("Failed to parse Twitch stream started at", "error", err, "started_at", streamMetadata.UserOrNull.Stream.StartedAt)
+				slog.WarnContext(ctx, "Failed to parse Twitch stream started at", "error", err, "started_at", streamMetadata.UserOrNull.Stream.StartedAt)
 			}
 		}
 	}
@@ -201,10 +201,12 @@ func fetchChannelFromTwitchTask(channel string) (twitchChannel, error) {
 	return result, nil
 }
 
-func fetchChannelsFromTwitch(channelLogins []string) (twitchChannelList, error) {
+func fetchChannelsFromTwitch(ctx context.Context, channelLogins []string) (twitchChannelList, error) {
 	result := make(twitchChannelList, 0, len(channelLogins))
 
-	job := newJob(fetchChannelFromTwitchTask, channelLogins).withWorkers(10)
+	job := newJob(func(channel string) (twitchChannel, error) {
+		return fetchChannelFromTwitchTask(ctx, channel)
+	}, channelLogins).withWorkers(10)
 	channels, errs, err := workerPoolDo(job)
 	if err != nil {
 		return result, err
@@ -215,7 +217,7 @@ func fetchChannelsFromTwitch(channelLogins []string) (twitchChannelList, error)
 	for i := range channels {
 		if errs[i] != nil {
 			failed++
-			slog.Error("Failed to fetch Twitch channel", "channel", channelLogins[i], "error", errs[i])
+			slog.ErrorContext(ctx, "Failed to fetch Twitch channel", "channel", channelLogins[i], "error", errs[i])
 			continue
 		}
 
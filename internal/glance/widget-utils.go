@@ -9,11 +9,16 @@ import (
 	"fmt"
 	"io"
 	"math/rand/v2"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"golang.org/x/net/http/httpproxy"
 )
 
 var (
@@ -23,15 +28,224 @@ var (
 
 const defaultClientTimeout = 5 * time.Second
 
-var defaultHTTPClient = &http.Client{
-	Timeout: defaultClientTimeout,
+// The clients that widgets make their requests with. Each application builds its own from its
+// config rather than changing shared ones, so that a reload never swaps out a client that an
+// update started by the previous config is still using.
+type widgetHTTPClients struct {
+	standard *http.Client
+	insecure *http.Client
+	// has no timeout of its own since requests are limited by the widget's timeout
+	extension *http.Client
+	// used by newClient, see the comment there
+	newTransport func(allowInsecure bool, customize func(*http.Transport)) http.RoundTripper
+}
+
+// Builds a client for widgets that need to change how the connections are made, such as by
+// going through a proxy of their own, while keeping the rest of the connection settings, the
+// headers and the user agent. Responses don't get cached since they depend on more than the URL.
+func (c *widgetHTTPClients) newClient(timeout time.Duration, allowInsecure bool, customize func(*http.Transport)) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: c.newTransport(allowInsecure, customize)}
+}
+
+// Used for requests made without the context of a widget, in which case the clients
+// don't have any of the connection settings from the config
+var fallbackWidgetHTTPClients = newWidgetHTTPClients(&httpTransportOptionsField{}, nil, nil, "", nil, nil)
+
+type widgetHTTPClientsContextKey struct{}
+
+func contextWithWidgetHTTPClients(ctx context.Context, clients *widgetHTTPClients) context.Context {
+	return context.WithValue(ctx, widgetHTTPClientsContextKey{}, clients)
 }
 
-var defaultInsecureHTTPClient = &http.Client{
-	Timeout: defaultClientTimeout,
-	Transport: &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	},
+func widgetHTTPClientsFromContext(ctx context.Context) *widgetHTTPClients {
+	if clients, ok := ctx.Value(widgetHTTPClientsContextKey{}).(*widgetHTTPClients); ok && clients != nil {
+		return clients
+	}
+
+	return fallbackWidgetHTTPClients
+}
+
+// Returns the client that skips verifying certificates when allowInsecure is set
+func widgetHTTPClient(ctx context.Context, allowInsecure bool) *http.Client {
+	clients := widgetHTTPClientsFromContext(ctx)
+	return ternary(allowInsecure, clients.insecure, clients.standard)
+}
+
+type widgetRequestHeadersContextKey struct{}
+
+func contextWithWidgetRequestHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return context.WithValue(ctx, widgetRequestHeadersContextKey{}, headers)
+}
+
+// Adds the headers from the widget's headers property to every request made with the
+// widget's context. They're set after the widget has set its own headers, so any header
+// with the same name gets replaced while the rest of the widget's headers are kept.
+type widgetRequestHeadersTransport struct {
+	base http.RoundTripper
+}
+
+func (t *widgetRequestHeadersTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	headers, _ := request.Context().Value(widgetRequestHeadersContextKey{}).(map[string]string)
+	if len(headers) == 0 {
+		return t.base.RoundTrip(request)
+	}
+
+	// round trippers must not modify the original request
+	request = request.Clone(request.Context())
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	return t.base.RoundTrip(request)
+}
+
+type widgetUserAgentContextKey struct{}
+
+func contextWithWidgetUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, widgetUserAgentContextKey{}, userAgent)
+}
+
+// Sends the user agent from the widget's user-agent property with every request made
+// with the widget's context, replacing whichever one the widget set by itself. Requests
+// that end up without one get the one from the server's user-agent, or the default if
+// that isn't set, rather than the one from Go's HTTP client, so a User-Agent the widget
+// set by itself takes precedence over the server's.
+type widgetUserAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *widgetUserAgentTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	userAgent, _ := request.Context().Value(widgetUserAgentContextKey{}).(string)
+	if userAgent == "" {
+		if request.Header.Get("User-Agent") != "" {
+			return t.base.RoundTrip(request)
+		}
+
+		userAgent = t.userAgent
+	}
+
+	// round trippers must not modify the original request
+	request = request.Clone(request.Context())
+	request.Header.Set("User-Agent", userAgent)
+
+	return t.base.RoundTrip(request)
+}
+
+func defaultWidgetUserAgent() string {
+	return "Glance/" + buildVersion + " (+https://github.com/glanceapp/glance)"
+}
+
+// Builds the clients for all widget requests from the connection settings in the config.
+// The cache backend can be nil, in which case responses don't get cached.
+func newWidgetHTTPClients(
+	options *httpTransportOptionsField,
+	proxy func(*http.Request) (*url.URL, error),
+	cacheBackend widgetCacheBackend,
+	cacheKeyPrefix string,
+	userAgent *string,
+	clientCertificate *tls.Certificate,
+) *widgetHTTPClients {
+	withUserAgent := func(transport http.RoundTripper) http.RoundTripper {
+		agent := defaultWidgetUserAgent()
+		if userAgent != nil {
+			agent = *userAgent
+		}
+
+		return &widgetUserAgentTransport{base: transport, userAgent: agent}
+	}
+
+	newTransport := func(allowInsecure bool, customize func(*http.Transport)) http.RoundTripper {
+		base := options.newTransport(allowInsecure, proxy, clientCertificate)
+
+		var transport http.RoundTripper = base
+		if customize != nil {
+			customize(base)
+		} else if cacheBackend != nil {
+			transport = &sharedCacheTransport{
+				base:      transport,
+				backend:   cacheBackend,
+				keyPrefix: cacheKeyPrefix,
+			}
+		}
+
+		// the headers get added first so that they're part of the cache key, and after
+		// the user agent so that a User-Agent in the widget's headers takes precedence
+		return withUserAgent(&widgetRequestHeadersTransport{base: transport})
+	}
+
+	return &widgetHTTPClients{
+		standard:     &http.Client{Timeout: defaultClientTimeout, Transport: newTransport(false, nil)},
+		insecure:     &http.Client{Timeout: defaultClientTimeout, Transport: newTransport(true, nil)},
+		extension:    &http.Client{Transport: withUserAgent(options.newTransport(false, proxy, clientCertificate))},
+		newTransport: newTransport,
+	}
+}
+
+func isWidgetProxyURLValid(proxyURL string) error {
+	// the error from parsing isn't included since it contains the URL, which may include credentials
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return errors.New("invalid URL")
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" && parsed.Scheme != "socks5" {
+		return fmt.Errorf("URL scheme must be one of http, https or socks5, got %q", parsed.Scheme)
+	}
+
+	if parsed.Host == "" {
+		return fmt.Errorf("URL must include a host")
+	}
+
+	return nil
+}
+
+// Sends requests through the proxy unless the host matches one of the entries in
+// noProxy, which follow the same rules as the NO_PROXY environment variable. Returns
+// nil when no proxy is set so that the environment variables get used instead.
+func newWidgetProxyFunc(proxyURL string, noProxy []string) func(*http.Request) (*url.URL, error) {
+	if proxyURL == "" {
+		return nil
+	}
+
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  proxyURL,
+		HTTPSProxy: proxyURL,
+		NoProxy:    strings.Join(noProxy, ","),
+	}).ProxyFunc()
+
+	return func(r *http.Request) (*url.URL, error) {
+		return proxyFunc(r.URL)
+	}
+}
+
+// Keeps the status code of a failed request around so that
+// it can be used to decide whether retrying makes sense
+type statusCodeError struct {
+	statusCode int
+	err        error
+}
+
+func (e *statusCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusCodeError) Unwrap() error {
+	return e.err
+}
+
+// Network errors and server errors are likely to go away on their own, unlike
+// client errors such as 404 or 429. Errors that can't be told apart are not transient,
+// so fetches need to return a statusCodeError for their server errors to be retried,
+// which decodeJsonFromRequest, decodeXmlFromRequest and the RSS and Docker fetches do.
+func isTransientFetchError(err error) bool {
+	var statusErr *statusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
 
 type requestDoer interface {
@@ -66,12 +280,12 @@ func decodeJsonFromRequest[T any](client requestDoer, request *http.Request) (T,
 	if response.StatusCode != http.StatusOK {
 		truncatedBody, _ := limitStringLength(string(body), 256)
 
-		return result, fmt.Errorf(
+		return result, &statusCodeError{response.StatusCode, fmt.Errorf(
 			"unexpected status code %d for %s, response: %s",
 			response.StatusCode,
 			request.URL,
 			truncatedBody,
-		)
+		)}
 	}
 
 	err = json.Unmarshal(body, &result)
@@ -106,12 +320,12 @@ func decodeXmlFromRequest[T any](client requestDoer, request *http.Request) (T,
 	if response.StatusCode != http.StatusOK {
 		truncatedBody, _ := limitStringLength(string(body), 256)
 
-		return result, fmt.Errorf(
+		return result, &statusCodeError{response.StatusCode, fmt.Errorf(
 			"unexpected status code %d for %s, response: %s",
 			response.StatusCode,
 			request.URL,
 			truncatedBody,
-		)
+		)}
 	}
 
 	err = xml.Unmarshal(body, &result)
@@ -5,11 +5,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"html/template"
 	"log/slog"
+	"maps"
 	"math"
+	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"sync/atomic"
+	texttemplate "text/template"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -17,73 +24,100 @@ import (
 
 var widgetIDCounter atomic.Uint64
 
+// Every widget type that can be used in the config, keyed by the name used in its type property
+var widgetTypes = map[string]func() widget{
+	"calendar":          func() widget { return &calendarWidget{} },
+	"calendar-legacy":   func() widget { return &oldCalendarWidget{} },
+	"clock":             func() widget { return &clockWidget{} },
+	"weather":           func() widget { return &weatherWidget{} },
+	"bookmarks":         func() widget { return &bookmarksWidget{} },
+	"iframe":            func() widget { return &iframeWidget{} },
+	"page-embed":        func() widget { return &pageEmbedWidget{} },
+	"html":              func() widget { return &htmlWidget{} },
+	"hacker-news":       func() widget { return &hackerNewsWidget{} },
+	"releases":          func() widget { return &releasesWidget{} },
+	"videos":            func() widget { return &videosWidget{} },
+	"markets":           func() widget { return &marketsWidget{} },
+	"reddit":            func() widget { return &redditWidget{} },
+	"rss":               func() widget { return &rssWidget{} },
+	"monitor":           func() widget { return &monitorWidget{} },
+	"twitch-top-games":  func() widget { return &twitchGamesWidget{} },
+	"twitch-channels":   func() widget { return &twitchChannelsWidget{} },
+	"lobsters":          func() widget { return &lobstersWidget{} },
+	"change-detection":  func() widget { return &changeDetectionWidget{} },
+	"repository":        func() widget { return &repositoryWidget{} },
+	"search":            func() widget { return &searchWidget{} },
+	"extension":         func() widget { return &extensionWidget{} },
+	"group":             func() widget { return &groupWidget{} },
+	"dns-stats":         func() widget { return &dnsStatsWidget{} },
+	"split-column":      func() widget { return &splitColumnWidget{} },
+	"custom-api":        func() widget { return &customAPIWidget{} },
+	"docker-containers": func() widget { return &dockerContainersWidget{} },
+	"server-stats":      func() widget { return &serverStatsWidget{} },
+}
+
 func newWidget(widgetType string) (widget, error) {
-	var w widget
-
-	switch widgetType {
-	case "calendar":
-		w = &calendarWidget{}
-	case "calendar-legacy":
-		w = &oldCalendarWidget{}
-	case "clock":
-		w = &clockWidget{}
-	case "weather":
-		w = &weatherWidget{}
-	case "bookmarks":
-		w = &bookmarksWidget{}
-	case "iframe":
-		w = &iframeWidget{}
-	case "html":
-		w = &htmlWidget{}
-	case "hacker-news":
-		w = &hackerNewsWidget{}
-	case "releases":
-		w = &releasesWidget{}
-	case "videos":
-		w = &videosWidget{}
-	case "markets", "stocks":
-		w = &marketsWidget{}
-	case "reddit":
-		w = &redditWidget{}
-	case "rss":
-		w = &rssWidget{}
-	case "monitor":
-		w = &monitorWidget{}
-	case "twitch-top-games":
-		w = &twitchGamesWidget{}
-	case "twitch-channels":
-		w = &twitchChannelsWidget{}
-	case "lobsters":
-		w = &lobstersWidget{}
-	case "change-detection":
-		w = &changeDetectionWidget{}
-	case "repository":
-		w = &repositoryWidget{}
-	case "search":
-		w = &searchWidget{}
-	case "extension":
-		w = &extensionWidget{}
-	case "group":
-		w = &groupWidget{}
-	case "dns-stats":
-		w = &dnsStatsWidget{}
-	case "split-column":
-		w = &splitColumnWidget{}
-	case "custom-api":
-		w = &customAPIWidget{}
-	case "docker-containers":
-		w = &dockerContainersWidget{}
-	case "server-stats":
-		w = &serverStatsWidget{}
-	default:
+	// legacy name of the markets widget, remove in v0.10.0
+	if widgetType == "stocks" {
+		widgetType = "markets"
+	}
+
+	newTypedWidget, exists := widgetTypes[widgetType]
+	if !exists {
 		return nil, fmt.Errorf("unknown widget type: %s", widgetType)
 	}
 
+	w := newTypedWidget()
 	w.setID(widgetIDCounter.Add(1))
 
 	return w, nil
 }
 
+// Placeholder for a widget whose type doesn't exist, which only fails once widgets get
+// initialized so that the error can say which page and column the widget is in
+type unknownWidget struct {
+	widgetBase `yaml:",inline"`
+}
+
+func (widget *unknownWidget) initialize() error {
+	if suggestions := closestWidgetTypes(widget.Type); len(suggestions) == 1 {
+		return fmt.Errorf("unknown widget type, did you mean %s?", suggestions[0])
+	} else if len(suggestions) > 1 {
+		return fmt.Errorf("unknown widget type, did you mean one of %s?", strings.Join(suggestions, ", "))
+	}
+
+	return fmt.Errorf("unknown widget type, available types are %s", strings.Join(slices.Sorted(maps.Keys(widgetTypes)), ", "))
+}
+
+func (widget *unknownWidget) Render() template.HTML {
+	return ""
+}
+
+// Returns up to three of the known types which are the fewest edits away from the given one, as
+// long as they're close enough for it to likely be a typo, along with the ones it's the start of
+func closestWidgetTypes(widgetType string) []string {
+	widgetType = strings.ToLower(strings.TrimSpace(widgetType))
+	maxDistance := max(2, len(widgetType)/3)
+
+	distances := make(map[string]int)
+	for name := range widgetTypes {
+		distance := levenshteinDistance(widgetType, name)
+		if distance <= maxDistance || (len(widgetType) >= 3 && strings.HasPrefix(name, widgetType)) {
+			distances[name] = distance
+		}
+	}
+
+	closest := slices.SortedFunc(maps.Keys(distances), func(a, b string) int {
+		if distances[a] != distances[b] {
+			return distances[a] - distances[b]
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	return closest[:min(3, len(closest))]
+}
+
 type widgets []widget
 
 func (w *widgets) UnmarshalYAML(node *yaml.Node) error {
@@ -93,25 +127,146 @@ func (w *widgets) UnmarshalYAML(node *yaml.Node) error {
 		return err
 	}
 
-	for _, node := range nodes {
-		meta := struct {
-			Type string `yaml:"type"`
-		}{}
-
-		if err := node.Decode(&meta); err != nil {
+	for i := range nodes {
+		widget, err := newWidgetFromYAMLNode(&nodes[i])
+		if err != nil {
 			return err
 		}
 
-		widget, err := newWidget(meta.Type)
+		*w = append(*w, widget)
+	}
+
+	return nil
+}
+
+func newWidgetFromYAMLNode(node *yaml.Node) (widget, error) {
+	meta := struct {
+		Type         string            `yaml:"type"`
+		Use          string            `yaml:"use"`
+		Timeout      *durationField    `yaml:"timeout"`
+		Retries      int               `yaml:"retries"`
+		RetryBackoff *durationField    `yaml:"retry-backoff"`
+		TitleURL     string            `yaml:"title-url"`
+		Headers      map[string]string `yaml:"headers"`
+		UserAgent    *string           `yaml:"user-agent"`
+		TitlePrefix  string            `yaml:"title-prefix"`
+	}{}
+
+	if err := node.Decode(&meta); err != nil {
+		return nil, err
+	}
+
+	if meta.Timeout != nil && *meta.Timeout <= 0 {
+		return nil, fmt.Errorf("widget timeout must be greater than 0")
+	}
+
+	if meta.Retries < 0 {
+		return nil, fmt.Errorf("widget retries must be 0 or greater")
+	}
+
+	if meta.RetryBackoff != nil && *meta.RetryBackoff <= 0 {
+		return nil, fmt.Errorf("widget retry-backoff must be greater than 0")
+	}
+
+	if meta.TitleURL != "" {
+		if err := isWidgetTitleURLValid(meta.TitleURL); err != nil {
+			return nil, fmt.Errorf("widget title-url: %v", err)
+		}
+	}
+
+	for name, value := range meta.Headers {
+		if !httpHeaderNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("widget headers contains an invalid header name %s", name)
+		}
+
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("widget headers: value of header %s cannot contain line breaks", name)
+		}
+	}
+
+	if meta.UserAgent != nil {
+		if strings.TrimSpace(*meta.UserAgent) == "" {
+			return nil, fmt.Errorf("widget user-agent cannot be empty when set")
+		}
+
+		if strings.ContainsAny(*meta.UserAgent, "\r\n") {
+			return nil, fmt.Errorf("widget user-agent cannot contain line breaks")
+		}
+	}
+
+	var titlePrefixTemplate *texttemplate.Template
+	if meta.TitlePrefix != "" {
+		var err error
+		titlePrefixTemplate, err = texttemplate.New("title-prefix").Parse(meta.TitlePrefix)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("widget title-prefix: %v", err)
 		}
+	}
 
-		if err = node.Decode(widget); err != nil {
-			return err
+	if meta.Use != "" {
+		if meta.Type != "" {
+			return nil, fmt.Errorf("widget cannot have both a type and use a definition (%s)", meta.Use)
 		}
 
-		*w = append(*w, widget)
+		// the definition is shared by every place it's used in, so there's nothing these could apply to
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if key := node.Content[i].Value; key != "use" {
+				return nil, fmt.Errorf("widget using definition %s cannot have other properties such as %s, set them in the definition instead", meta.Use, key)
+			}
+		}
+
+		return &widgetReference{Use: meta.Use}, nil
+	}
+
+	widget, err := newWidget(meta.Type)
+	if err != nil {
+		if meta.Type == "" {
+			return nil, err
+		}
+
+		return &unknownWidget{widgetBase: widgetBase{Type: meta.Type}}, nil
+	}
+
+	if err = node.Decode(widget); err != nil {
+		return nil, err
+	}
+
+	if source := widget.getDataSource(); source != nil {
+		if err := applyWidgetDataSource(widget, source); err != nil {
+			return nil, fmt.Errorf("widget data-source: %v", err)
+		}
+	}
+
+	widget.setRequestHeaders(meta.Headers)
+	widget.setTitlePrefixTemplate(titlePrefixTemplate)
+
+	return widget, nil
+}
+
+// Title URLs can either be absolute or start with a single / in which case
+// they're relative to the base URL, such as when linking to another page
+func isWidgetTitleURLValid(titleURL string) error {
+	if strings.HasPrefix(titleURL, "/") {
+		if strings.HasPrefix(titleURL, "//") {
+			return fmt.Errorf("must not start with //: %s", titleURL)
+		}
+
+		return nil
+	}
+
+	parsed, err := url.Parse(titleURL)
+	if err != nil {
+		return fmt.Errorf("parsing URL: %v", err)
+	}
+
+	if parsed.Scheme == "" {
+		return fmt.Errorf("must either start with / or be an absolute URL: %s", titleURL)
+	}
+
+	// these run whatever the URL contains rather than navigating somewhere,
+	// such as data:text/html,... which would render a page of its own
+	if scheme := strings.ToLower(parsed.Scheme); scheme == "javascript" || scheme == "data" || scheme == "vbscript" {
+		return fmt.Errorf("%s URLs are not allowed: %s", scheme, titleURL)
 	}
 
 	return nil
@@ -122,6 +277,9 @@ type widget interface {
 	Render() template.HTML
 	GetType() string
 	GetID() uint64
+	GetTitle() string
+	GetTitleURL() string
+	IsVisible() bool
 
 	initialize() error
 	requiresUpdate(*time.Time) bool
@@ -130,6 +288,95 @@ type widget interface {
 	setID(uint64)
 	handleRequest(w http.ResponseWriter, r *http.Request)
 	setHideHeader(bool)
+	setTitleTag(string)
+	getTimeout() time.Duration
+	getStableID() string
+	getCSSClass() string
+	getCSSID() string
+	setStableID(string)
+	getError() error
+	getNotice() error
+	getSharedCacheTTL() time.Duration
+	setTitleURL(string)
+	getRetryOptions() (int, time.Duration)
+	getDependencies() []string
+	getRequestHeaders() map[string]string
+	getUserAgent() string
+	getDataSource() *widgetDataSource
+	getHTTPClients() *widgetHTTPClients
+	setRequestHeaders(map[string]string)
+	setTitlePrefixTemplate(*texttemplate.Template)
+	isAwaitingFirstUpdate() bool
+	isMocked() bool
+	completeMockedUpdate(hasMockData bool)
+}
+
+const defaultWidgetTimeout = 10 * time.Second
+
+// Bounds the duration of a single update of the widget and records it in the metrics.
+// Containers are left alone since each of their widgets goes through here separately.
+func updateWidgetWithTimeout(ctx context.Context, widget widget) {
+	if _, ok := widget.(widgetContainer); ok {
+		widget.update(ctx)
+		return
+	}
+
+	if timeout := widget.getTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ctx = contextWithWidgetHTTPClients(ctx, widget.getHTTPClients())
+	ctx = contextWithWidgetRequestHeaders(ctx, widget.getRequestHeaders())
+	ctx = contextWithWidgetUserAgent(ctx, widget.getUserAgent())
+	ctx = contextWithWidgetCache(ctx, widget.getStableID(), widget.getSharedCacheTTL())
+
+	startedAt := time.Now()
+	if _, ok := widget.(*sharedWidgetPlacement); ok {
+		// retries happen inside of the shared widget's update so that it can lock each attempt separately
+		widget.update(ctx)
+	} else {
+		updateWidgetWithRetries(ctx, widget)
+	}
+	metrics.observeWidgetFetch(widget.GetType(), pageSlugFromContext(ctx), time.Since(startedAt), widget.getError() != nil)
+}
+
+const defaultWidgetRetryBackoff = time.Second
+
+// Retries updates that failed with a transient error, waiting twice as long
+// before each retry, as long as there's enough time left before the deadline
+func updateWidgetWithRetries(ctx context.Context, widget widget) {
+	if widget.isMocked() {
+		updateWidgetWithMockData(widget)
+		return
+	}
+
+	retries, backoff := widget.getRetryOptions()
+	retryTransientFetchErrors(ctx, retries, backoff, func() error {
+		widget.update(ctx)
+		return widget.getError()
+	})
+}
+
+func retryTransientFetchErrors(ctx context.Context, retries int, backoff time.Duration, attemptUpdate func() error) {
+	for attempt := 0; ; attempt++ {
+		err := attemptUpdate()
+		if err == nil || attempt >= retries || ctx.Err() != nil || !isTransientFetchError(err) {
+			return
+		}
+
+		delay := backoff << attempt
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
 }
 
 type cacheType int
@@ -141,31 +388,86 @@ const (
 )
 
 type widgetBase struct {
-	ID                  uint64           `yaml:"-"`
-	Providers           *widgetProviders `yaml:"-"`
-	Type                string           `yaml:"type"`
-	Title               string           `yaml:"title"`
-	TitleURL            string           `yaml:"title-url"`
-	CSSClass            string           `yaml:"css-class"`
-	CustomCacheDuration durationField    `yaml:"cache"`
-	ContentAvailable    bool             `yaml:"-"`
-	WIP                 bool             `yaml:"-"`
-	Error               error            `yaml:"-"`
-	Notice              error            `yaml:"-"`
-	templateBuffer      bytes.Buffer     `yaml:"-"`
-	cacheDuration       time.Duration    `yaml:"-"`
-	cacheType           cacheType        `yaml:"-"`
-	nextUpdate          time.Time        `yaml:"-"`
-	updateRetriedTimes  int              `yaml:"-"`
-	HideHeader          bool             `yaml:"-"`
+	ID                  uint64            `yaml:"-"`
+	StableID            string            `yaml:"id"` // unlike ID, stays the same across restarts and reloads
+	Providers           *widgetProviders  `yaml:"-"`
+	Type                string            `yaml:"type"`
+	Title               string            `yaml:"title"`
+	TitleURL            string            `yaml:"title-url"`
+	TitlePrefix         string            `yaml:"title-prefix"`
+	CSSClass            string            `yaml:"css-class"`
+	CSSID               string            `yaml:"css-id"`
+	CustomCacheDuration durationField     `yaml:"cache"`
+	RefreshOffset       durationField     `yaml:"refresh-offset"`
+	Timeout             durationField     `yaml:"timeout"`
+	Retries             int               `yaml:"retries"`
+	RetryBackoff        durationField     `yaml:"retry-backoff"`
+	UserAgent           string            `yaml:"user-agent"`
+	DependsOn           []string          `yaml:"depends-on"`
+	MobileOrder         int               `yaml:"mobile-order"`
+	Schedule            *scheduleField    `yaml:"schedule"`
+	VisibleDuring       *scheduleField    `yaml:"visible-during"`
+	OnError             widgetErrorMode   `yaml:"on-error"`
+	Mock                bool              `yaml:"mock"`
+	DataSource          *widgetDataSource `yaml:"data-source"`
+	ContentAvailable    bool              `yaml:"-"`
+	WIP                 bool              `yaml:"-"`
+	Error               error             `yaml:"-"`
+	Notice              error             `yaml:"-"`
+	templateBuffer      bytes.Buffer      `yaml:"-"`
+	cacheDuration       time.Duration     `yaml:"-"`
+	cacheType           cacheType         `yaml:"-"`
+	nextUpdate          time.Time         `yaml:"-"`
+	updateRetriedTimes  int               `yaml:"-"`
+	HideHeader          bool              `yaml:"-"`
+	// when the widget last updated without a fatal error, used to indicate stale data
+	LastSuccessfulUpdate time.Time `yaml:"-"`
+	// decoded in newWidgetFromYAMLNode since some widgets have a headers property of their own
+	requestHeaders map[string]string
+	// comes from the widget-title-tag of the column the widget is in
+	titleTag string
+	// parsed in newWidgetFromYAMLNode and executed with the widget on every render
+	titlePrefixTemplate *texttemplate.Template
+	RenderedTitlePrefix string `yaml:"-"`
+	// set when mock is enabled but the widget has no mock data to show
+	MissingMockData bool `yaml:"-"`
+}
+
+type widgetErrorMode string
+
+const (
+	widgetErrorModeDefault   widgetErrorMode = ""
+	widgetErrorModeHide      widgetErrorMode = "hide"
+	widgetErrorModeShowError widgetErrorMode = "show-error"
+	widgetErrorModeShowStale widgetErrorMode = "show-stale"
+)
+
+func (m *widgetErrorMode) UnmarshalYAML(node *yaml.Node) error {
+	var value string
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+
+	switch mode := widgetErrorMode(value); mode {
+	case widgetErrorModeDefault, widgetErrorModeHide, widgetErrorModeShowError, widgetErrorModeShowStale:
+		*m = mode
+		return nil
+	default:
+		return fmt.Errorf("invalid on-error value %s, must be one of hide, show-error or show-stale", value)
+	}
 }
 
 type widgetProviders struct {
-	assetResolver func(string) string
+	assetResolver     func(string) string
+	userAssetResolver func(string) string
+	fetchJitter       time.Duration
+	// widgets with a shorter cache duration get clamped to it, 0 when disabled
+	minRefreshInterval time.Duration
+	httpClients        *widgetHTTPClients
 }
 
 func (w *widgetBase) requiresUpdate(now *time.Time) bool {
-	if w.cacheType == cacheTypeInfinite {
+	if w.cacheType == cacheTypeInfinite || w.isOutsideSchedule(*now) {
 		return false
 	}
 
@@ -176,6 +478,20 @@ func (w *widgetBase) requiresUpdate(now *time.Time) bool {
 	return now.After(w.nextUpdate)
 }
 
+func (w *widgetBase) isOutsideSchedule(now time.Time) bool {
+	return w.Schedule != nil && !w.Schedule.isActive(now)
+}
+
+func (w *widgetBase) IsOutsideSchedule() bool {
+	return w.isOutsideSchedule(time.Now())
+}
+
+// Checked by the templates of pages and containers on every render rather than when rendering
+// the widget itself, since some widgets only render once and keep the resulting HTML around
+func (w *widgetBase) IsVisible() bool {
+	return w.VisibleDuring == nil || w.VisibleDuring.isActive(time.Now())
+}
+
 func (w *widgetBase) IsWIP() bool {
 	return w.WIP
 }
@@ -184,6 +500,30 @@ func (w *widgetBase) update(ctx context.Context) {
 
 }
 
+func (w *widgetBase) getTimeout() time.Duration {
+	if w.Timeout <= 0 {
+		return defaultWidgetTimeout
+	}
+
+	return time.Duration(w.Timeout)
+}
+
+func (w *widgetBase) IsShowingStaleContent() bool {
+	return w.OnError == widgetErrorModeShowStale && w.Error != nil && w.ContentAvailable
+}
+
+func (w *widgetBase) GetTitle() string {
+	return w.Title
+}
+
+func (w *widgetBase) GetTitleURL() string {
+	return w.TitleURL
+}
+
+func (w *widgetBase) setTitleURL(titleURL string) {
+	w.TitleURL = titleURL
+}
+
 func (w *widgetBase) GetID() uint64 {
 	return w.ID
 }
@@ -192,6 +532,114 @@ func (w *widgetBase) setID(id uint64) {
 	w.ID = id
 }
 
+func (w *widgetBase) getError() error {
+	return w.Error
+}
+
+func (w *widgetBase) getNotice() error {
+	return w.Notice
+}
+
+// How long responses fetched by the widget can be kept in the shared cache, which is
+// as long as the widget itself keeps its data without the jitter and offsets
+func (w *widgetBase) getSharedCacheTTL() time.Duration {
+	switch w.cacheType {
+	case cacheTypeDuration:
+		return w.cacheDuration
+	case cacheTypeOnTheHour:
+		now := time.Now()
+		return now.Truncate(time.Hour).Add(time.Hour).Sub(now)
+	default:
+		return 0
+	}
+}
+
+func (w *widgetBase) getRequestHeaders() map[string]string {
+	return w.requestHeaders
+}
+
+func (w *widgetBase) getUserAgent() string {
+	return w.UserAgent
+}
+
+func (w *widgetBase) getDataSource() *widgetDataSource {
+	return w.DataSource
+}
+
+// Nil for widgets which haven't been given their providers, which then use the fallback clients
+func (w *widgetBase) getHTTPClients() *widgetHTTPClients {
+	if w.Providers == nil {
+		return nil
+	}
+
+	return w.Providers.httpClients
+}
+
+func (w *widgetBase) setRequestHeaders(headers map[string]string) {
+	w.requestHeaders = headers
+}
+
+func (w *widgetBase) setTitlePrefixTemplate(t *texttemplate.Template) {
+	w.titlePrefixTemplate = t
+}
+
+func (w *widgetBase) getDependencies() []string {
+	return w.DependsOn
+}
+
+func (w *widgetBase) isAwaitingFirstUpdate() bool {
+	return w.nextUpdate.IsZero()
+}
+
+func (w *widgetBase) isMocked() bool {
+	return w.Mock
+}
+
+func (w *widgetBase) completeMockedUpdate(hasMockData bool) {
+	w.canContinueUpdateAfterHandlingErr(nil)
+	w.MissingMockData = !hasMockData
+}
+
+func (w *widgetBase) getRetryOptions() (int, time.Duration) {
+	if w.RetryBackoff <= 0 {
+		return w.Retries, defaultWidgetRetryBackoff
+	}
+
+	return w.Retries, time.Duration(w.RetryBackoff)
+}
+
+func (w *widgetBase) getCSSClass() string {
+	return w.CSSClass
+}
+
+func (w *widgetBase) getCSSID() string {
+	return w.CSSID
+}
+
+func (w *widgetBase) getStableID() string {
+	return w.StableID
+}
+
+func (w *widgetBase) setStableID(id string) {
+	w.StableID = id
+}
+
+func (w *widgetBase) setTitleTag(tag string) {
+	w.titleTag = tag
+}
+
+func (w *widgetBase) ShowsTitleText() bool {
+	return w.titleTag == "" || w.titleTag == "text" || w.titleTag == "both"
+}
+
+func (w *widgetBase) ShowsTitleIcon() bool {
+	return w.titleTag == "icon" || w.titleTag == "both"
+}
+
+func (w *widgetBase) TitleIcon() template.HTML {
+	return widgetTitleIcon(w.Type)
+}
+
 func (w *widgetBase) setHideHeader(value bool) {
 	w.HideHeader = value
 }
@@ -206,9 +654,33 @@ func (w *widgetBase) GetType() string {
 
 func (w *widgetBase) setProviders(providers *widgetProviders) {
 	w.Providers = providers
+
+	// widgets which update on the hour or never aren't affected since they can't update more often than hourly
+	if floor := providers.minRefreshInterval; floor > 0 && w.cacheType == cacheTypeDuration && w.cacheDuration < floor {
+		slog.Warn(
+			"Widget cache duration is below the server's min-refresh-interval, using the minimum instead",
+			"widget", w.StableID, "cache", w.cacheDuration, "min-refresh-interval", floor,
+		)
+		w.cacheDuration = floor
+	}
 }
 
 func (w *widgetBase) renderTemplate(data any, t *template.Template) template.HTML {
+	if w.Error != nil {
+		switch w.OnError {
+		case widgetErrorModeHide:
+			return ""
+		case widgetErrorModeShowError:
+			// render the error in place of any previously fetched content
+			if w.ContentAvailable {
+				w.ContentAvailable = false
+				defer func() { w.ContentAvailable = true }()
+			}
+		}
+	}
+
+	w.RenderedTitlePrefix = w.renderTitlePrefix(data)
+
 	w.templateBuffer.Reset()
 	err := t.Execute(&w.templateBuffer, data)
 	if err != nil {
@@ -234,6 +706,32 @@ func (w *widgetBase) renderTemplate(data any, t *template.Template) template.HTM
 	return template.HTML(w.templateBuffer.String())
 }
 
+// Widgets which keep their rendered HTML around rather than rendering on every request still
+// need to be rendered again when they have a schedule, since whether the placeholder is shown
+// depends on the time of the render rather than when the HTML was cached
+func (w *widgetBase) renderCachedTemplate(cached template.HTML, data any, t *template.Template) template.HTML {
+	if w.Schedule == nil {
+		return cached
+	}
+
+	return w.renderTemplate(data, t)
+}
+
+// The prefix gets left out if it fails to execute rather than failing the whole widget
+func (w *widgetBase) renderTitlePrefix(data any) string {
+	if w.titlePrefixTemplate == nil {
+		return ""
+	}
+
+	var prefix strings.Builder
+	if err := w.titlePrefixTemplate.Execute(&prefix, data); err != nil {
+		slog.Error("Failed to execute title prefix template", "widget", w.StableID, "error", err)
+		return ""
+	}
+
+	return prefix.String()
+}
+
 func (w *widgetBase) withTitle(title string) *widgetBase {
 	if w.Title == "" {
 		w.Title = title
@@ -309,29 +807,66 @@ func (w *widgetBase) canContinueUpdateAfterHandlingErr(err error) bool {
 
 		w.withError(nil)
 		w.withNotice(err)
+		w.LastSuccessfulUpdate = time.Now()
 		return true
 	}
 
 	w.withNotice(nil)
 	w.withError(nil)
 	w.scheduleNextUpdate()
+	w.LastSuccessfulUpdate = time.Now()
 	return true
 }
 
 func (w *widgetBase) getNextUpdateTime() time.Time {
 	now := time.Now()
+	var next time.Time
 
-	if w.cacheType == cacheTypeDuration {
-		return now.Add(w.cacheDuration)
-	}
-
-	if w.cacheType == cacheTypeOnTheHour {
-		return now.Add(time.Duration(
+	switch w.cacheType {
+	case cacheTypeDuration:
+		next = now.Add(w.cacheDuration)
+	case cacheTypeOnTheHour:
+		next = now.Add(time.Duration(
 			((60-now.Minute())*60)-now.Second(),
 		) * time.Second)
+	default:
+		return time.Time{}
 	}
 
-	return time.Time{}
+	// spread out updates so that widgets with the same cache duration
+	// don't all hit their APIs at the same time
+	next = next.Add(time.Duration(w.RefreshOffset))
+
+	// widgets which loaded at the same time get their first refresh offset by an amount that
+	// doesn't change across restarts, which is enough to keep them from lining up after it
+	if w.LastSuccessfulUpdate.IsZero() {
+		next = next.Add(w.stableFetchJitter())
+	} else {
+		next = next.Add(w.randomFetchJitter())
+	}
+
+	return next
+}
+
+func (w *widgetBase) randomFetchJitter() time.Duration {
+	if w.Providers == nil || w.Providers.fetchJitter <= 0 {
+		return 0
+	}
+
+	return rand.N(w.Providers.fetchJitter)
+}
+
+// Derived from the widget's stable ID rather than being random so
+// that the offset of each widget stays the same across restarts
+func (w *widgetBase) stableFetchJitter() time.Duration {
+	if w.Providers == nil || w.Providers.fetchJitter <= 0 {
+		return 0
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(w.StableID))
+
+	return time.Duration(hash.Sum64() % uint64(w.Providers.fetchJitter))
 }
 
 func (w *widgetBase) scheduleNextUpdate() *widgetBase {
@@ -348,7 +883,9 @@ func (w *widgetBase) scheduleEarlyUpdate() *widgetBase {
 		w.updateRetriedTimes = 5
 	}
 
-	nextEarlyUpdate := time.Now().Add(time.Duration(math.Pow(float64(w.updateRetriedTimes), 2)) * time.Minute)
+	// jittered as well, otherwise widgets whose first update failed for the same
+	// reason, such as the network being down on startup, would all retry together
+	nextEarlyUpdate := time.Now().Add(time.Duration(math.Pow(float64(w.updateRetriedTimes), 2))*time.Minute + w.randomFetchJitter())
 	nextUsualUpdate := w.getNextUpdateTime()
 
 	if nextEarlyUpdate.After(nextUsualUpdate) {